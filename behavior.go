@@ -0,0 +1,89 @@
+package errors
+
+import "io/fs"
+
+type timeouter = interface{ Timeout() bool }
+type temporary = interface{ Temporary() bool }
+
+// IsTimeout 错误是否表示超时，如 net.Error、context.DeadlineExceeded。
+// 沿错误链(包括聚合的多个错误，不包括次要错误)查找第一个实现了 `Timeout() bool` 的错误，
+// 返回其结果
+func IsTimeout(err error) bool {
+	return firstBehavior(err, func(err error) (result, ok bool) {
+		if t, ok := err.(timeouter); ok {
+			return t.Timeout(), true
+		}
+		return false, false
+	})
+}
+
+// IsTemporary 错误是否表示暂时性的失败。
+// 沿错误链(包括聚合的多个错误，不包括次要错误)查找第一个实现了 `Temporary() bool` 的错误，
+// 返回其结果
+func IsTemporary(err error) bool {
+	return firstBehavior(err, func(err error) (result, ok bool) {
+		if t, ok := err.(temporary); ok {
+			return t.Temporary(), true
+		}
+		return false, false
+	})
+}
+
+// firstBehavior 沿错误链查找第一个能判断的错误
+// 聚合的多个错误中任意一个判断为 true 即返回 true
+func firstBehavior(err error, check func(err error) (result, ok bool)) bool {
+	for err != nil {
+		if result, ok := check(err); ok {
+			return result
+		}
+		if errs := UnwrapMulti(err); len(errs) > 0 {
+			for _, e := range errs {
+				if firstBehavior(e, check) {
+					return true
+				}
+			}
+			return false
+		}
+		err = UnwrapOnce(err)
+	}
+	return false
+}
+
+// IsNotExist 错误是否表示文件或目录不存在。
+// 与 os.IsNotExist 不同，会展开本包的包装错误及聚合的多个错误
+func IsNotExist(err error) bool { return isChainOf(err, fs.ErrNotExist) }
+
+// IsExist 错误是否表示文件或目录已存在。
+// 与 os.IsExist 不同，会展开本包的包装错误及聚合的多个错误
+func IsExist(err error) bool { return isChainOf(err, fs.ErrExist) }
+
+// IsPermission 错误是否表示没有权限。
+// 与 os.IsPermission 不同，会展开本包的包装错误及聚合的多个错误
+func IsPermission(err error) bool { return isChainOf(err, fs.ErrPermission) }
+
+// isChainOf 错误树(不包括次要错误)中是否有 target
+// 与标准库 errors.Is 不同 Go 1.20 之前也会展开聚合的多个错误
+func isChainOf(err, target error) bool {
+	var found bool
+	Walk(err, func(node Node) WalkAction {
+		if node.Edge == EdgeSecondary {
+			return WalkSkip
+		}
+		if isSentinel(node.Err, target) {
+			found = true
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return found
+}
+
+// withStack, withPrefix, withSecondaryError 转发被包装错误的 Timeout, Temporary 行为(按 IsTimeout, IsTemporary 判断)，
+// 从而 Wrap 等包装 net.Error 后仍然满足 net.Error 接口，os.IsTimeout 也能识别
+
+func (e *withStack) Timeout() bool            { return IsTimeout(e.error) }
+func (e *withStack) Temporary() bool          { return IsTemporary(e.error) }
+func (e *withPrefix) Timeout() bool           { return IsTimeout(e.error) }
+func (e *withPrefix) Temporary() bool         { return IsTemporary(e.error) }
+func (e *withSecondaryError) Timeout() bool   { return IsTimeout(e.cause) }
+func (e *withSecondaryError) Temporary() bool { return IsTemporary(e.cause) }
@@ -0,0 +1,74 @@
+package errors_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestForwardTimeout(t *testing.T) {
+	cause := &timeoutErr{timeout: true}
+	ne, ok := errors.Wrap(cause, "dial").(net.Error)
+	if !ok || !ne.Timeout() || ne.Temporary() {
+		t.Errorf("wrappers should keep net.Error behavior")
+	}
+	if !os.IsTimeout(errors.WithStack(context.DeadlineExceeded)) ||
+		!os.IsTimeout(errors.WithSecondary(errors.Wrap(cause, "dial"), io.EOF)) {
+		t.Errorf("os.IsTimeout should see through wrappers")
+	}
+	if errors.WithStack(io.EOF).(net.Error).Timeout() {
+		t.Errorf("unexpected timeout")
+	}
+	// 不转发的包装错误 As 仍能找到真正的网络错误
+	var target *timeoutErr
+	if !errors.As(errors.WithHint(cause, "check network"), &target) || target != cause {
+		t.Errorf("As should find the network error in the chain")
+	}
+	// 次要错误不影响行为
+	err := errors.WithSecondary(errors.New("a"), &timeoutErr{timeout: true})
+	if errors.IsTimeout(err) || err.(net.Error).Timeout() {
+		t.Errorf("secondary errors should be ignored")
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	for _, c := range []struct {
+		err                error
+		timeout, temporary bool
+	}{
+		{nil, false, false},
+		{io.EOF, false, false},
+		{errors.Wrap(&timeoutErr{timeout: true}, "x"), true, false},
+		{errors.Join(io.EOF, errors.WithStack(context.DeadlineExceeded)), true, true},
+		{errors.Errorf("wait: %w", context.DeadlineExceeded), true, true},
+	} {
+		if got := errors.IsTimeout(c.err); got != c.timeout {
+			t.Errorf("IsTimeout(%v) = %v, want %v", c.err, got, c.timeout)
+		}
+		if got := errors.IsTemporary(c.err); got != c.temporary {
+			t.Errorf("IsTemporary(%v) = %v, want %v", c.err, got, c.temporary)
+		}
+	}
+}
+
+func TestIsNotExist(t *testing.T) {
+	_, errOpen := os.Open("/path/not/exist")
+	err := errors.Wrap(errOpen, "load config")
+	if !errors.IsNotExist(err) || errors.IsExist(err) || errors.IsPermission(err) {
+		t.Errorf("IsNotExist should see through wrappers")
+	}
+	if !errors.IsNotExist(errors.Join(io.EOF, errors.WithStack(fs.ErrNotExist))) {
+		t.Errorf("IsNotExist should see joined errors")
+	}
+	if !errors.IsExist(errors.WithStack(fs.ErrExist)) || !errors.IsPermission(errors.Wrap(fs.ErrPermission, "x")) {
+		t.Errorf("IsExist / IsPermission")
+	}
+	if errors.IsNotExist(errors.WithSecondary(io.EOF, errOpen)) || errors.IsNotExist(nil) {
+		t.Errorf("secondary errors should be ignored")
+	}
+}
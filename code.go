@@ -0,0 +1,150 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Code 机器可读的错误码，值为字符串或整数，可选地属于某个域(命名空间)。
+// Code 可以比较，值和域都相同时两个错误码相等
+type Code struct {
+	// Domain 错误码所属的域 如 `auth`、`order`，可以为空
+	Domain string
+	// value 错误码的值 string 或 int
+	value any
+}
+
+// StringCode 创建一个字符串错误码
+func StringCode(domain, value string) Code {
+	return Code{Domain: domain, value: value}
+}
+
+// IntCode 创建一个整数错误码
+func IntCode(domain string, value int) Code {
+	return Code{Domain: domain, value: value}
+}
+
+// IsZero 是否是零值
+func (c Code) IsZero() bool { return c.value == nil }
+
+// Value 错误码的值 string 或 int，零值时为 nil
+func (c Code) Value() any { return c.value }
+
+// Int 错误码为整数时返回其值
+func (c Code) Int() (int, bool) {
+	n, ok := c.value.(int)
+	return n, ok
+}
+
+// String 形如 `domain:value`，域为空时只有值
+func (c Code) String() string {
+	var v string
+	switch value := c.value.(type) {
+	case string:
+		v = value
+	case int:
+		v = strconv.Itoa(value)
+	}
+	if c.Domain == "" {
+		return v
+	}
+	return c.Domain + ":" + v
+}
+
+// WithCode 给错误附加错误码，不改变错误消息。
+// 错误码在使用 %+v 格式化动词时打印，可通过 GetCode 获取
+func WithCode(err error, code Code) error {
+	if err == nil || code.IsZero() {
+		return err
+	}
+	return &withCode{cause: err, code: code}
+}
+
+// NewCode 新建一个带错误码的错误实例，带堆栈。
+// code 为零值时与 New 相同，不带错误码
+func NewCode(code Code, msg string) error {
+	err := &fundamental{
+		string:     msg,
+		stack:      callers(),
+		redactable: safeRedactable(msg),
+	}
+	if code.IsZero() {
+		return err
+	}
+	return &withCode{cause: err, code: code}
+}
+
+// GetCode 获取错误树(不包括次要错误)中最外层的错误码
+func GetCode(err error) (code Code, ok bool) {
+	Walk(err, func(node Node) WalkAction {
+		if node.Edge == EdgeSecondary {
+			return WalkSkip
+		}
+		if e, is := node.Err.(*withCode); is {
+			code, ok = e.code, true
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return
+}
+
+var _ error = (*withCode)(nil)
+var _ ErrorPrinter = (*withCode)(nil)
+var _ fmt.Formatter = (*withCode)(nil)
+
+type withCode struct {
+	cause error
+	code  Code
+}
+
+func (e *withCode) Error() string                 { return e.cause.Error() }
+func (e *withCode) Cause() error                  { return e.cause }
+func (e *withCode) Unwrap() error                 { return e.cause }
+func (e *withCode) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// Is 错误码相同即视为相同的错误:
+// Is(err, NewCode(code, "")) 判断 err 中是否带有错误码 code
+func (e *withCode) Is(target error) bool {
+	code, ok := GetCode(target)
+	return ok && code == e.code
+}
+
+// PrintError implements ErrorPrinter.
+func (e *withCode) PrintError(p Printer) (next error) {
+	p.PrintDetailf("code: %v", Safe(e.code))
+	return e.cause
+}
+
+// encodedCode 错误码编码时的载荷
+type encodedCode struct {
+	Domain string `json:"domain,omitempty"`
+	String string `json:"string,omitempty"`
+	Int    *int   `json:"int,omitempty"`
+}
+
+func init() {
+	codeKey := GetTypeKey(&withCode{})
+	RegisterWrapperEncoder(codeKey, func(err error) (string, json.RawMessage) {
+		code := err.(*withCode).code
+		ec := encodedCode{Domain: code.Domain}
+		switch v := code.value.(type) {
+		case string:
+			ec.String = v
+		case int:
+			ec.Int = &v
+		}
+		b, _ := json.Marshal(ec)
+		return "", b
+	})
+	RegisterWrapperDecoder(codeKey, func(cause error, _ string, payload json.RawMessage) error {
+		var ec encodedCode
+		json.Unmarshal(payload, &ec)
+		code := StringCode(ec.Domain, ec.String)
+		if ec.Int != nil {
+			code = IntCode(ec.Domain, *ec.Int)
+		}
+		return &withCode{cause: cause, code: code}
+	})
+}
@@ -0,0 +1,65 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+var (
+	codeNotFound = errors.StringCode("user", "NotFound")
+	errNotFound  = errors.NewCode(codeNotFound, "user not found")
+)
+
+func TestCode(t *testing.T) {
+	if codeNotFound.String() != "user:NotFound" || errors.IntCode("", 404).String() != "404" {
+		t.Errorf("Code.String() got %s", codeNotFound)
+	}
+	if n, ok := errors.IntCode("http", 404).Int(); !ok || n != 404 {
+		t.Errorf("Code.Int() got %v %v", n, ok)
+	}
+	if !(errors.Code{}).IsZero() || errors.WithCode(io.EOF, errors.Code{}) != io.EOF {
+		t.Errorf("zero Code")
+	}
+	if _, ok := errors.GetCode(errors.NewCode(errors.Code{}, "x")); ok {
+		t.Errorf("NewCode with zero Code should not carry a code")
+	}
+
+	err := errors.Wrap(errors.WithCode(io.EOF, errors.IntCode("db", 1205)), "query")
+	if err.Error() != "query: EOF" {
+		t.Errorf("WithCode should not change message, got %q", err.Error())
+	}
+	if code, ok := errors.GetCode(errors.WithCode(err, codeNotFound)); !ok || code != codeNotFound {
+		t.Errorf("GetCode should return the outermost code, got %v", code)
+	}
+	if _, ok := errors.GetCode(errors.WithSecondary(io.EOF, err)); ok {
+		t.Errorf("GetCode should ignore secondary errors")
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "code: db:1205") {
+		t.Errorf("%%+v should show the code")
+	}
+	if frame := errors.NewReport(errors.Unwrap(errNotFound)).Stack[0]; !strings.HasSuffix(frame.Function, "errors_test.init") {
+		t.Errorf("NewCode stack should start at the caller, got %s", frame.Function)
+	}
+}
+
+func TestCodeIs(t *testing.T) {
+	err := errors.Wrap(errors.NewCode(codeNotFound, "user 42 not found"), "load")
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("errors with the same code should match")
+	}
+	if errors.Is(err, errors.NewCode(errors.StringCode("order", "NotFound"), "")) || errors.Is(io.EOF, errNotFound) {
+		t.Errorf("different domains should not match")
+	}
+	decoded := roundTrip(t, err)
+	if code, _ := errors.GetCode(decoded); code != codeNotFound || !errors.Is(decoded, errNotFound) {
+		t.Errorf("code should survive encoding, got %v", code)
+	}
+	decoded = roundTrip(t, errors.WithCode(io.EOF, errors.IntCode("db", 1205)))
+	if code, _ := errors.GetCode(decoded); code != errors.IntCode("db", 1205) {
+		t.Errorf("int code should survive encoding, got %v", code)
+	}
+}
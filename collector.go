@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Collector 逐个收集错误，最后聚合为一个错误，可以并发使用。
+// 每次 Add 都会记录调用处的堆栈。
+//
+//	var c errors.Collector
+//	for _, item := range items {
+//		if err := process(item); err != nil {
+//			c.Add(err)
+//		}
+//	}
+//	return c.Err()
+//
+// 零值可以直接使用，此时不限制保留的错误数
+type Collector struct {
+	mu    sync.Mutex
+	limit int
+	errs  []error
+	// 超出上限而未保留的错误数
+	omitted int
+}
+
+// NewCollector 创建一个最多保留 limit 个错误的 Collector，
+// 超出的错误只计数，聚合时以 `and N more errors` 汇总；limit <= 0 表示不限制
+func NewCollector(limit int) *Collector {
+	return &Collector{limit: limit}
+}
+
+// Add 添加一个错误，err 为 nil 时忽略
+func (c *Collector) Add(err error) {
+	if err == nil {
+		return
+	}
+	c.add(&withStack{
+		error: err,
+		stack: callers(),
+	})
+}
+
+// Addf 按指定格式新建一个错误并添加，格式同 Errorf
+func (c *Collector) Addf(format string, args ...any) {
+	c.add(errorf(callers(), format, args...))
+}
+
+func (c *Collector) add(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.limit > 0 && len(c.errs) >= c.limit {
+		c.omitted++
+		return
+	}
+	c.errs = append(c.errs, err)
+}
+
+// Len 已添加的错误数(包括超出上限而未保留的)
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.errs) + c.omitted
+}
+
+// Err 聚合已添加的所有错误，没有错误时返回 nil
+func (c *Collector) Err() error {
+	c.mu.Lock()
+	errs := make([]error, len(c.errs), len(c.errs)+1)
+	copy(errs, c.errs)
+	if c.omitted > 0 {
+		errs = append(errs, &omittedErrors{count: c.omitted})
+	}
+	c.mu.Unlock()
+
+	err := join(errs...)
+	if err == nil {
+		return nil
+	}
+	return &withStack{
+		error: err,
+		stack: callers(),
+	}
+}
+
+var _ error = (*omittedErrors)(nil)
+var _ ErrorPrinter = (*omittedErrors)(nil)
+var _ fmt.Formatter = (*omittedErrors)(nil)
+
+// omittedErrors 超出上限而未保留的错误的汇总
+type omittedErrors struct {
+	count int
+}
+
+func (e *omittedErrors) Error() string {
+	return fmt.Sprintf("and %d more errors", e.count)
+}
+
+func (e *omittedErrors) Format(s fmt.State, verb rune) {
+	FormatError(e, s, verb)
+}
+
+// PrintError implements ErrorPrinter.
+func (e *omittedErrors) PrintError(p Printer) (next error) {
+	p.Printf("and %v more errors", Safe(e.count))
+	return nil
+}
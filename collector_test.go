@@ -0,0 +1,56 @@
+package errors_test
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestCollector(t *testing.T) {
+	var c errors.Collector
+	if c.Err() != nil || c.Len() != 0 {
+		t.Errorf("empty collector should return nil")
+	}
+	c.Add(nil)
+	c.Add(io.EOF)
+	c.Addf("item %d: %w", 2, io.ErrUnexpectedEOF)
+	err := c.Err()
+	t.Logf("%+v", err)
+	if c.Len() != 2 || err.Error() != "EOF\nitem 2: unexpected EOF" {
+		t.Errorf("Len()=%d, Error()=%q", c.Len(), err.Error())
+	}
+	// 每个错误都带有调用 Add 处的堆栈
+	for _, e := range errors.Unwrap(err).(interface{ Unwrap() []error }).Unwrap() {
+		frame := errors.NewReport(e).Stack[0]
+		if !strings.HasSuffix(frame.Function, "errors_test.TestCollector") {
+			t.Errorf("stack should start at Add call site, got %s", frame.Function)
+		}
+	}
+	if errors.Redact(err) != "‹×›\nitem ‹×›: ‹×›" {
+		t.Errorf("Redact got %q", errors.Redact(err))
+	}
+}
+
+func TestCollectorLimit(t *testing.T) {
+	c := errors.NewCollector(3)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Addf("failed")
+		}()
+	}
+	wg.Wait()
+	err := c.Err()
+	t.Logf("%+v", err)
+	if c.Len() != 10 || err.Error() != "failed\nfailed\nfailed\nand 7 more errors" {
+		t.Errorf("Len()=%d, Error()=%q", c.Len(), err.Error())
+	}
+	if errors.Redact(err) != err.Error() {
+		t.Errorf("summary should be safe, got %q", errors.Redact(err))
+	}
+}
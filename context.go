@@ -0,0 +1,58 @@
+package errors
+
+import "context"
+
+// ContextExtractor 从 context 中提取需要附加到错误上的键值对，如 trace ID、租户、请求 ID
+type ContextExtractor func(ctx context.Context) []Field
+
+var contextExtractors []ContextExtractor
+
+// RegisterContextExtractor 注册 context 键值对提取函数，
+// WrapCtx, WithContext 会调用所有已注册的函数并将结果附加到错误上。
+// 应当在 init 中注册
+func RegisterContextExtractor(extractor ContextExtractor) {
+	if extractor != nil {
+		contextExtractors = append(contextExtractors, extractor)
+	}
+}
+
+// contextFields 使用所有已注册的提取函数从 context 中提取键值对
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	var fields []Field
+	for _, extract := range contextExtractors {
+		fields = append(fields, extract(ctx)...)
+	}
+	return fields
+}
+
+// WithContext 将从 context 中提取的键值对(见 RegisterContextExtractor)附加到错误上，
+// 效果同 WithFields
+func WithContext(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if fields := contextFields(ctx); len(fields) > 0 {
+		return &withFields{cause: err, fields: fields}
+	}
+	return err
+}
+
+// WrapCtx 同 Wrap，并附加从 context 中提取的键值对(见 RegisterContextExtractor)
+func WrapCtx(ctx context.Context, err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	if msg != "" {
+		err = WithMessage(err, msg)
+	}
+	if fields := contextFields(ctx); len(fields) > 0 {
+		err = &withFields{cause: err, fields: fields}
+	}
+	return &withStack{
+		error: err,
+		stack: callers(),
+	}
+}
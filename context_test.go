@@ -0,0 +1,53 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+type traceKey struct{}
+
+func init() {
+	errors.RegisterContextExtractor(func(ctx context.Context) []errors.Field {
+		if id, ok := ctx.Value(traceKey{}).(string); ok {
+			return []errors.Field{{Key: "trace_id", Value: id}}
+		}
+		return nil
+	})
+}
+
+func TestWrapCtx(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceKey{}, "t-1")
+	err := errors.WrapCtx(ctx, io.EOF, "read")
+	if err.Error() != "read: EOF" {
+		t.Errorf("Error() got %q", err.Error())
+	}
+	if got := fmt.Sprint(errors.Fields(err)); got != "[{trace_id t-1}]" {
+		t.Errorf("Fields got %s", got)
+	}
+	if frame := errors.NewReport(err).Stack[0]; !strings.HasSuffix(frame.Function, "errors_test.TestWrapCtx") {
+		t.Errorf("stack should start at WrapCtx call site, got %s", frame.Function)
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "fields: trace_id=t-1") {
+		t.Errorf("%%+v should show context fields")
+	}
+	if errors.WrapCtx(ctx, nil, "x") != nil {
+		t.Errorf("WrapCtx(nil) want nil")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	if errors.WithContext(context.Background(), io.EOF) != io.EOF {
+		t.Errorf("no fields in context, error should be returned as is")
+	}
+	ctx := context.WithValue(context.Background(), traceKey{}, "t-2")
+	err := errors.WithFields(errors.WithContext(ctx, io.EOF), "trace_id", "override")
+	if got := fmt.Sprint(errors.Fields(err)); got != "[{trace_id override}]" {
+		t.Errorf("Fields got %s", got)
+	}
+}
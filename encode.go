@@ -0,0 +1,286 @@
+package errors
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// EncodedError 错误链的可序列化形式，可通过 JSON 跨进程传递。
+//
+// 叶子错误 Cause, Causes 均为空；
+// 包装单个错误时 Cause 非空；
+// 包装多个错误时 Causes 非空。
+type EncodedError struct {
+	// Type 原始错误类型 见 GetTypeKey
+	Type string `json:"type"`
+	// Message 叶子错误为完整消息；包装错误为前缀消息
+	Message string `json:"message,omitempty"`
+	// Redactable 带有脱敏标记的 Message，不安全的部分使用 ‹› 包围
+	Redactable string `json:"redactable,omitempty"`
+	// FullMessage 为 true 表示包装错误的 Message 已包含 cause 的消息
+	FullMessage bool `json:"fullMessage,omitempty"`
+	// Stack 符号化后的堆栈
+	Stack []StackFrame `json:"stack,omitempty"`
+	// StackTruncated 超出最大深度而未被记录的帧数
+	StackTruncated int `json:"stackTruncated,omitempty"`
+	// Payload 自定义错误类型的附加数据 见 RegisterLeafEncoder
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Secondary 次要错误
+	Secondary *EncodedError `json:"secondary,omitempty"`
+	// Cause 被包装的单个错误
+	Cause *EncodedError `json:"cause,omitempty"`
+	// Causes 被包装的多个错误
+	Causes []*EncodedError `json:"causes,omitempty"`
+}
+
+// LeafEncoder 编码叶子错误，返回完整消息及附加数据
+type LeafEncoder func(err error) (msg string, payload json.RawMessage)
+
+// WrapperEncoder 编码包装错误，返回前缀消息及附加数据
+type WrapperEncoder func(err error) (prefix string, payload json.RawMessage)
+
+// LeafDecoder 根据消息及附加数据还原叶子错误
+type LeafDecoder func(msg string, payload json.RawMessage) error
+
+// WrapperDecoder 根据被包装的错误、前缀消息及附加数据还原包装错误
+type WrapperDecoder func(cause error, prefix string, payload json.RawMessage) error
+
+// 注册表 应在 init 阶段注册，运行期只读
+var (
+	leafEncoders    = map[string]LeafEncoder{}
+	wrapperEncoders = map[string]WrapperEncoder{}
+	leafDecoders    = map[string]LeafDecoder{}
+	wrapperDecoders = map[string]WrapperDecoder{}
+)
+
+// RegisterLeafEncoder 为指定类型的叶子错误注册编码函数
+func RegisterLeafEncoder(typeKey string, enc LeafEncoder) { leafEncoders[typeKey] = enc }
+
+// RegisterWrapperEncoder 为指定类型的包装错误注册编码函数
+func RegisterWrapperEncoder(typeKey string, enc WrapperEncoder) { wrapperEncoders[typeKey] = enc }
+
+// RegisterLeafDecoder 为指定类型的叶子错误注册解码函数，
+// 解码时将还原为真实的 Go 类型，从而 Is/As 仍然可用
+func RegisterLeafDecoder(typeKey string, dec LeafDecoder) { leafDecoders[typeKey] = dec }
+
+// RegisterWrapperDecoder 为指定类型的包装错误注册解码函数
+func RegisterWrapperDecoder(typeKey string, dec WrapperDecoder) { wrapperDecoders[typeKey] = dec }
+
+// GetTypeKey 获取错误类型的唯一标识，形如 `*code.gopub.tech/errors.fundamental`
+// 对于解码得到的错误，返回其原始类型
+func GetTypeKey(err error) string {
+	if o, ok := err.(originalTyper); ok {
+		return o.originalType()
+	}
+	rt := reflect.TypeOf(err)
+	var ptr string
+	if rt.Kind() == reflect.Ptr {
+		ptr = "*"
+		rt = rt.Elem()
+	}
+	if rt.Name() == "" || rt.PkgPath() == "" {
+		return reflect.TypeOf(err).String()
+	}
+	return ptr + rt.PkgPath() + "." + rt.Name()
+}
+
+// errorTypeName 获取用于展示的错误类型名，形如 `*errors.fundamental`
+func errorTypeName(err error) string {
+	if o, ok := err.(originalTyper); ok {
+		key := o.originalType()
+		var ptr string
+		if strings.HasPrefix(key, "*") {
+			ptr, key = "*", key[1:]
+		}
+		if i := strings.LastIndex(key, "/"); i >= 0 {
+			key = key[i+1:]
+		}
+		return ptr + key
+	}
+	return reflect.TypeOf(err).String()
+}
+
+// EncodeError 将错误链编码为可序列化的形式
+func EncodeError(err error) EncodedError {
+	if err == nil {
+		return EncodedError{}
+	}
+	return *encodeError(err)
+}
+
+func encodeError(err error) *EncodedError {
+	if ef, ok := err.(*errorFormatter); ok {
+		return encodeError(ef.error) // 仅用于格式化 编码时透明
+	}
+	enc := &EncodedError{Type: GetTypeKey(err)}
+	if st, ok := GetStackTrace(err); ok {
+		enc.Stack = symbolize(st)
+	} else if fp, ok := err.(stackFramesProvider); ok {
+		enc.Stack = fp.StackFrames()
+	}
+	if t, ok := err.(stackTruncation); ok {
+		enc.StackTruncated = t.framesTruncated()
+	}
+	switch e := err.(type) {
+	case *fundamental:
+		enc.Message, enc.Redactable = e.string, string(e.redactable)
+		return enc
+	case *withStack:
+		enc.Cause = encodeError(e.error)
+		return enc
+	case *withPrefix:
+		enc.Message, enc.Redactable = e.string, string(e.redactable)
+		enc.Cause = encodeError(e.error)
+		return enc
+	case *withNewMessage:
+		enc.Message, enc.Redactable = e.message, string(e.redactable)
+		enc.FullMessage = true
+		enc.Cause = encodeError(e.cause)
+		return enc
+	case *withSecondaryError:
+		enc.Secondary = encodeError(e.secondaryError)
+		enc.Cause = encodeError(e.cause)
+		return enc
+	case *opaqueLeaf:
+		enc.Message, enc.Payload = e.msg, e.payload
+		enc.Redactable = string(e.redactable)
+		return enc
+	case *opaqueWrapper:
+		enc.Message, enc.FullMessage, enc.Payload = e.prefix, e.fullMessage, e.payload
+		enc.Redactable = string(e.redactable)
+		enc.Cause = encodeError(e.cause)
+		return enc
+	case *opaqueMulti:
+		enc.Message, enc.Payload = e.msg, e.payload
+		for _, cause := range e.causes {
+			enc.Causes = append(enc.Causes, encodeError(cause))
+		}
+		return enc
+	}
+
+	if cause := UnwrapOnce(err); cause != nil {
+		if we, ok := wrapperEncoders[enc.Type]; ok {
+			enc.Message, enc.Payload = we(err)
+		} else {
+			var isPrefix bool
+			enc.Message, isPrefix = extractPrefix(err, cause)
+			enc.FullMessage = !isPrefix
+		}
+		enc.Cause = encodeError(cause)
+		return enc
+	}
+
+	if le, ok := leafEncoders[enc.Type]; ok {
+		enc.Message, enc.Payload = le(err)
+	} else {
+		enc.Message = err.Error()
+	}
+	for _, cause := range UnwrapMulti(err) {
+		enc.Causes = append(enc.Causes, encodeError(cause))
+	}
+	return enc
+}
+
+// DecodeError 将编码后的错误链还原。
+// 已注册解码函数的类型还原为真实类型，
+// 本包的类型还原为等价结构，其他类型还原为保留了消息、类型、堆栈的不透明错误。
+func DecodeError(enc EncodedError) error {
+	if enc.Type == "" {
+		return nil
+	}
+	return decodeError(&enc)
+}
+
+func decodeError(enc *EncodedError) error {
+	var err error
+	switch {
+	case enc.Cause != nil:
+		err = decodeWrapper(enc, decodeError(enc.Cause))
+	case len(enc.Causes) > 0:
+		causes := make([]error, 0, len(enc.Causes))
+		for _, c := range enc.Causes {
+			causes = append(causes, decodeError(c))
+		}
+		if enc.Type == typeKeyJoinError {
+			err = &joinError{errs: causes}
+		} else {
+			err = &opaqueMulti{
+				msg:       enc.Message,
+				typeKey:   enc.Type,
+				causes:    causes,
+				frames:    enc.Stack,
+				truncated: enc.StackTruncated,
+				payload:   enc.Payload,
+			}
+		}
+	default:
+		err = decodeLeaf(enc)
+	}
+	return err
+}
+
+func decodeLeaf(enc *EncodedError) error {
+	if dec, ok := leafDecoders[enc.Type]; ok {
+		return dec(enc.Message, enc.Payload)
+	}
+	return &opaqueLeaf{
+		msg:        enc.Message,
+		redactable: decodeRedactable(enc),
+		typeKey:    enc.Type,
+		frames:     enc.Stack,
+		truncated:  enc.StackTruncated,
+		payload:    enc.Payload,
+	}
+}
+
+func decodeWrapper(enc *EncodedError, cause error) error {
+	if dec, ok := wrapperDecoders[enc.Type]; ok {
+		return dec(cause, enc.Message, enc.Payload)
+	}
+	switch enc.Type {
+	case typeKeyWithPrefix:
+		return &withPrefix{
+			error:      cause,
+			string:     enc.Message,
+			redactable: decodeRedactable(enc),
+		}
+	case typeKeyWithNewMessage:
+		return &withNewMessage{
+			cause:      cause,
+			message:    enc.Message,
+			redactable: decodeRedactable(enc),
+		}
+	case typeKeyWithSecondaryError:
+		var secondary error
+		if enc.Secondary != nil {
+			secondary = decodeError(enc.Secondary)
+		}
+		return &withSecondaryError{cause: cause, secondaryError: secondary}
+	}
+	return &opaqueWrapper{
+		cause:       cause,
+		prefix:      enc.Message,
+		redactable:  decodeRedactable(enc),
+		fullMessage: enc.FullMessage,
+		typeKey:     enc.Type,
+		frames:      enc.Stack,
+		truncated:   enc.StackTruncated,
+		payload:     enc.Payload,
+	}
+}
+
+// decodeRedactable 没有携带脱敏信息时 整条消息视为不安全
+func decodeRedactable(enc *EncodedError) redactable {
+	if enc.Redactable != "" {
+		return redactable(enc.Redactable)
+	}
+	return unsafeRedactable(enc.Message)
+}
+
+var (
+	typeKeyJoinError          = GetTypeKey(&joinError{})
+	typeKeyWithPrefix         = GetTypeKey(&withPrefix{})
+	typeKeyWithNewMessage     = GetTypeKey(&withNewMessage{})
+	typeKeyWithSecondaryError = GetTypeKey(&withSecondaryError{})
+)
@@ -0,0 +1,101 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+type codeErr struct {
+	Code int `json:"code"`
+}
+
+func (e *codeErr) Error() string { return fmt.Sprintf("code=%d", e.Code) }
+
+var errSentinel = fmt.Errorf("sentinel")
+
+func init() {
+	key := errors.GetTypeKey(&codeErr{})
+	errors.RegisterLeafEncoder(key, func(err error) (string, json.RawMessage) {
+		b, _ := json.Marshal(err)
+		return err.Error(), b
+	})
+	errors.RegisterLeafDecoder(key, func(msg string, payload json.RawMessage) error {
+		e := &codeErr{}
+		json.Unmarshal(payload, e)
+		return e
+	})
+	errors.RegisterLeafDecoder(errors.GetTypeKey(errSentinel), func(msg string, _ json.RawMessage) error {
+		if msg == errSentinel.Error() {
+			return errSentinel
+		}
+		return fmt.Errorf("%s", msg)
+	})
+}
+
+func roundTrip(t *testing.T, err error) error {
+	t.Helper()
+	b, e := json.Marshal(errors.EncodeError(err))
+	if e != nil {
+		t.Fatalf("Marshal failed: %v", e)
+	}
+	var enc errors.EncodedError
+	if e := json.Unmarshal(b, &enc); e != nil {
+		t.Fatalf("Unmarshal failed: %v", e)
+	}
+	return errors.DecodeError(enc)
+}
+
+func TestEncodeError(t *testing.T) {
+	if err := errors.DecodeError(errors.EncodeError(nil)); err != nil {
+		t.Errorf("want nil, got %v", err)
+	}
+	origin := errors.Wrapf(
+		errors.WithSecondary(
+			errors.Join(&codeErr{Code: 42}, errors.Errorf("wrap: %w", errSentinel)),
+			errLeafNew,
+		),
+		"prefix %d", 1,
+	)
+	err := roundTrip(t, origin)
+	t.Logf("%+v", err)
+	if err.Error() != origin.Error() {
+		t.Errorf("message mismatch:\nwant %q\ngot  %q", origin.Error(), err.Error())
+	}
+	if !errors.Is(roundTrip(t, errors.Errorf("wrap: %w", errSentinel)), errSentinel) {
+		t.Errorf("Is(sentinel) failed")
+	}
+	var ce *codeErr
+	if !errors.As(roundTrip(t, errors.Wrap(&codeErr{Code: 42}, "wrap")), &ce) || ce.Code != 42 {
+		t.Errorf("As(codeErr) failed: %v", ce)
+	}
+	detail := errors.Detail(err)
+	for _, want := range []string{
+		"*errors.withStack", "*errors.fundamental", "secondary error attachment",
+		"leafErr", "errors_test.TestEncodeError",
+	} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail should contain %q", want)
+		}
+	}
+}
+
+func TestEncodeForeignWrapper(t *testing.T) {
+	origin := fmt.Errorf("foreign: %w", &onlyJoin{errs: []error{errFmt, errLeafNew}})
+	err := roundTrip(t, origin)
+	t.Logf("%+v", err)
+	if err.Error() != origin.Error() {
+		t.Errorf("message mismatch:\nwant %q\ngot  %q", origin.Error(), err.Error())
+	}
+	if key := errors.GetTypeKey(err); key != "*fmt.wrapError" {
+		t.Errorf("type key want *fmt.wrapError, got %s", key)
+	}
+	// 再次编码 类型及堆栈应保持不变
+	again := roundTrip(t, err)
+	if errors.Detail(again) != errors.Detail(err) {
+		t.Errorf("re-encode mismatch:\n%s\n%s", errors.Detail(again), errors.Detail(err))
+	}
+}
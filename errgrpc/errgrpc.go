@@ -0,0 +1,208 @@
+// Package errgrpc 在错误与 gRPC 状态之间转换:
+// 按错误码、类别映射 gRPC 状态码，
+// 并将编码后的整条错误链(消息、类型、堆栈、次要错误)放在状态详情中，
+// 使客户端可以还原出与服务端相同的错误树。
+package errgrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"code.gopub.tech/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// CodeDomain 表示 gRPC 状态码的错误码域，见 Code
+const CodeDomain = "grpc"
+
+// DetailDomain 状态详情 errdetails.ErrorInfo 中编码后的错误链所属的域
+const DetailDomain = "code.gopub.tech/errors"
+
+// detailKey 编码后的错误链在 errdetails.ErrorInfo.Metadata 中的键
+const detailKey = "error"
+
+// Code 创建一个表示 gRPC 状态码的错误码，
+// 通过 errors.WithCode 附加到错误上后 ToStatus 会直接使用该状态码
+func Code(c codes.Code) errors.Code {
+	return errors.IntCode(CodeDomain, int(c))
+}
+
+var kindCodes = map[errors.Kind]codes.Code{
+	errors.KindInvalid:         codes.InvalidArgument,
+	errors.KindNotFound:        codes.NotFound,
+	errors.KindExist:           codes.AlreadyExists,
+	errors.KindPermission:      codes.PermissionDenied,
+	errors.KindUnauthenticated: codes.Unauthenticated,
+	errors.KindConflict:        codes.Aborted,
+	errors.KindUnavailable:     codes.Unavailable,
+	errors.KindTimeout:         codes.DeadlineExceeded,
+	errors.KindCanceled:        codes.Canceled,
+	errors.KindUnimplemented:   codes.Unimplemented,
+	errors.KindInternal:        codes.Internal,
+}
+
+// codeKinds gRPC 状态码对应的类别
+var codeKinds = map[codes.Code]errors.Kind{}
+
+func init() {
+	for kind, code := range kindCodes {
+		codeKinds[code] = kind
+	}
+}
+
+// grpcStatus gRPC 的状态错误 如 status.Error 创建的错误
+type grpcStatus = interface{ GRPCStatus() *status.Status }
+
+// StatusCode 获取错误对应的 gRPC 状态码，依次使用:
+//   - 域为 CodeDomain 的错误码(见 Code)
+//   - 错误链中 gRPC 的状态错误
+//   - 错误的类别(见 errors.KindOf)
+//
+// 都无法映射时返回 codes.Unknown。err 为 nil 时返回 codes.OK
+func StatusCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if code, ok := errors.GetCode(err); ok && code.Domain == CodeDomain {
+		if c, ok := code.Int(); ok {
+			return codes.Code(c)
+		}
+	}
+	var se grpcStatus
+	if errors.As(err, &se) {
+		if st := se.GRPCStatus(); st != nil {
+			return st.Code()
+		}
+	}
+	if c, ok := kindCodes[errors.KindOf(err)]; ok {
+		return c
+	}
+	return codes.Unknown
+}
+
+// ToStatus 将错误转换为 gRPC 状态，
+// 编码后的错误链(见 errors.EncodeError)放在 errdetails.ErrorInfo 类型的状态详情中。
+// err 本身就是 gRPC 的状态错误时原样返回其状态；
+// 错误链中包装了 gRPC 的状态错误时，沿用其消息及状态详情。
+// err 为 nil 时返回 OK 状态
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if se, ok := err.(grpcStatus); ok {
+		if st := se.GRPCStatus(); st != nil {
+			return st
+		}
+	}
+	p := &spb.Status{Code: int32(StatusCode(err)), Message: err.Error()}
+	var se grpcStatus
+	if errors.As(err, &se) {
+		if inner := se.GRPCStatus(); inner != nil {
+			// `rpc error: code = NotFound desc = msg` => `msg`
+			p.Message = strings.Replace(p.Message, se.(error).Error(), inner.Message(), 1)
+			for _, detail := range inner.Proto().GetDetails() {
+				if !isEncodedChain(detail) { // 旧的错误链已包含在新编码的错误链中
+					p.Details = append(p.Details, detail)
+				}
+			}
+		}
+	}
+	st := status.FromProto(p)
+	b, e := json.Marshal(errors.EncodeError(err))
+	if e != nil {
+		return st
+	}
+	info := &errdetails.ErrorInfo{
+		Reason:   errors.GetTypeKey(err),
+		Domain:   DetailDomain,
+		Metadata: map[string]string{detailKey: string(b)},
+	}
+	if withDetails, e := st.WithDetails(info); e == nil {
+		return withDetails
+	}
+	return st
+}
+
+// isEncodedChain 状态详情是否是本包编码的错误链
+func isEncodedChain(detail *anypb.Any) bool {
+	var info errdetails.ErrorInfo
+	return detail.MessageIs(&info) && detail.UnmarshalTo(&info) == nil &&
+		info.GetDomain() == DetailDomain
+}
+
+// FromStatus 将 gRPC 状态还原为错误。
+// 状态详情中有编码后的错误链时还原为原来的错误树(见 errors.DecodeError)，
+// 否则使用状态消息创建错误并附加对应的类别。
+// 返回的错误实现了 `GRPCStatus() *status.Status`，可以获取原始的状态码及状态详情。
+// st 为 nil 或 OK 状态时返回 nil
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	var err error
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != DetailDomain {
+			continue
+		}
+		var enc errors.EncodedError
+		if json.Unmarshal([]byte(info.GetMetadata()[detailKey]), &enc) == nil {
+			err = errors.DecodeError(enc)
+		}
+		break
+	}
+	if err == nil {
+		err = &statusMessage{msg: st.Message()}
+		if kind, ok := codeKinds[st.Code()]; ok {
+			err = errors.WithKind(err, kind)
+		}
+	}
+	return &statusError{cause: err, st: st}
+}
+
+// FromError 将客户端收到的错误还原为错误树，
+// err 不是 gRPC 的状态错误时原样返回
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(grpcStatus); !ok {
+		return err
+	}
+	st, _ := status.FromError(err)
+	return FromStatus(st)
+}
+
+var _ error = (*statusMessage)(nil)
+
+// statusMessage 没有编码的错误链时 使用状态消息创建的错误
+type statusMessage struct{ msg string }
+
+func (e *statusMessage) Error() string { return e.msg }
+
+var _ error = (*statusError)(nil)
+var _ errors.ErrorPrinter = (*statusError)(nil)
+var _ fmt.Formatter = (*statusError)(nil)
+
+// statusError 由 gRPC 状态还原的错误
+type statusError struct {
+	cause error
+	st    *status.Status
+}
+
+func (e *statusError) Error() string                 { return e.cause.Error() }
+func (e *statusError) Cause() error                  { return e.cause }
+func (e *statusError) Unwrap() error                 { return e.cause }
+func (e *statusError) GRPCStatus() *status.Status    { return e.st }
+func (e *statusError) Format(s fmt.State, verb rune) { errors.FormatError(e, s, verb) }
+
+// PrintError implements errors.ErrorPrinter.
+func (e *statusError) PrintError(p errors.Printer) (next error) {
+	p.PrintDetailf("grpc status: %v", errors.Safe(e.st.Code()))
+	return e.cause
+}
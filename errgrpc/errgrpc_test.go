@@ -0,0 +1,177 @@
+package errgrpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+	"code.gopub.tech/errors/errgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var errNotFound = errors.New("service not found")
+
+func TestStatusCode(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want codes.Code
+	}{
+		{nil, codes.OK},
+		{io.EOF, codes.Unknown},
+		{errors.Wrap(fs.ErrNotExist, "open"), codes.NotFound},
+		{errors.WithKind(io.EOF, errors.KindConflict), codes.Aborted},
+		{errors.WithStack(context.DeadlineExceeded), codes.DeadlineExceeded},
+		{errors.Wrap(status.Error(codes.ResourceExhausted, "quota"), "call"), codes.ResourceExhausted},
+		{errors.WithCode(fs.ErrNotExist, errgrpc.Code(codes.FailedPrecondition)), codes.FailedPrecondition},
+	} {
+		if got := errgrpc.StatusCode(c.err); got != c.want {
+			t.Errorf("StatusCode(%v) = %s, want %s", c.err, got, c.want)
+		}
+	}
+}
+
+func TestStatusRoundTrip(t *testing.T) {
+	if errgrpc.ToStatus(nil).Code() != codes.OK || errgrpc.FromStatus(status.New(codes.OK, "")) != nil {
+		t.Errorf("nil error should be OK status")
+	}
+	userNotFound := errors.StringCode("user", "NotFound")
+	err := errors.WithSecondary(
+		errors.Wrap(errors.WithCode(errors.WithKind(errNotFound, errors.KindNotFound), userNotFound), "lookup"),
+		errors.New("cleanup failed"),
+	)
+	st := errgrpc.ToStatus(err)
+	if st.Code() != codes.NotFound || st.Message() != "lookup: service not found" {
+		t.Errorf("got %s %q", st.Code(), st.Message())
+	}
+	decoded := errgrpc.FromStatus(st)
+	if decoded.Error() != err.Error() || !errors.Is(decoded, errNotFound) {
+		t.Errorf("decoded error should match the original: %v", decoded)
+	}
+	if errors.KindOf(decoded) != errors.KindNotFound || status.Code(decoded) != codes.NotFound {
+		t.Errorf("decoded error should keep the kind and status code")
+	}
+	if code, _ := errors.GetCode(decoded); code != userNotFound {
+		t.Errorf("decoded error should keep the application code, got %v", code)
+	}
+	detail := fmt.Sprintf("%+v", decoded)
+	if !strings.Contains(detail, "cleanup failed") || !strings.Contains(detail, "errgrpc_test.TestStatusRoundTrip") {
+		t.Errorf("secondary errors and stacks should be preserved:\n%s", detail)
+	}
+
+	// 没有错误链的状态
+	decoded = errgrpc.FromStatus(status.New(codes.PermissionDenied, "denied"))
+	if decoded.Error() != "denied" || errors.KindOf(decoded) != errors.KindPermission {
+		t.Errorf("got %v %s", decoded, errors.KindOf(decoded))
+	}
+	if errgrpc.ToStatus(errors.Wrap(decoded, "call")).Code() != codes.PermissionDenied {
+		t.Errorf("status code should be propagated")
+	}
+}
+
+func TestToStatusFromGRPCStatus(t *testing.T) {
+	info := &errdetails.ErrorInfo{Reason: "USER_MISSING", Domain: "example.com"}
+	orig, _ := status.New(codes.NotFound, "user missing").WithDetails(info)
+	if st := errgrpc.ToStatus(orig.Err()); st.Message() != "user missing" || len(st.Details()) != 1 {
+		t.Errorf("status errors should be passed through: %q %v", st.Message(), st.Details())
+	}
+
+	st := errgrpc.ToStatus(errors.Wrap(orig.Err(), "call"))
+	if st.Code() != codes.NotFound || st.Message() != "call: user missing" {
+		t.Errorf("got %s %q", st.Code(), st.Message())
+	}
+	details := st.Details()
+	if len(details) != 2 || details[0].(*errdetails.ErrorInfo).GetReason() != "USER_MISSING" {
+		t.Errorf("original details should be kept: %v", details)
+	}
+
+	// 客户端收到后再次返回 旧的错误链不会重复
+	st = errgrpc.ToStatus(errors.Wrap(errgrpc.FromStatus(st), "proxy"))
+	if st.Message() != "proxy: call: user missing" || len(st.Details()) != 2 {
+		t.Errorf("got %q %v", st.Message(), st.Details())
+	}
+}
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if req.GetService() == "" {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	}
+	return nil, errors.Wrapf(errors.WithKind(errNotFound, errors.KindNotFound), "check %s", req.GetService())
+}
+
+func (healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, ss grpc_health_v1.Health_WatchServer) error {
+	ss.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+	return errors.WithKind(errors.Errorf("watch %s", req.GetService()), errors.KindUnavailable)
+}
+
+func dial(t *testing.T) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(errgrpc.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(errgrpc.StreamServerInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, healthServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(errgrpc.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(errgrpc.StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestInterceptors(t *testing.T) {
+	client := dial(t)
+	ctx := context.Background()
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "db"})
+	t.Logf("%+v", err)
+	if err.Error() != "check db: service not found" || !errors.Is(err, errNotFound) {
+		t.Errorf("unary error should be decoded: %v", err)
+	}
+	if status.Code(err) != codes.NotFound || errors.KindOf(err) != errors.KindNotFound {
+		t.Errorf("unary error should keep status code and kind")
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "errgrpc_test.healthServer.Check") {
+		t.Errorf("server stack should be preserved")
+	}
+
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "cache"})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	_, err = stream.Recv()
+	if err.Error() != "watch cache" || status.Code(err) != codes.Unavailable || errors.KindOf(err) != errors.KindUnavailable {
+		t.Errorf("stream error should be decoded: %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+// Package errhttp 将错误转换为 HTTP 响应:
+// 按错误码、类别及注册的规则映射状态码，
+// 以 RFC 7807 `application/problem+json` 格式输出错误，
+// 并提供恢复 panic 的中间件。
+package errhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+
+	"code.gopub.tech/errors"
+)
+
+// CodeDomain 表示 HTTP 状态码的错误码域，见 Code
+const CodeDomain = "http"
+
+// ContentType RFC 7807 错误响应的内容类型
+const ContentType = "application/problem+json"
+
+// StatusClientClosedRequest 客户端取消请求时使用的状态码(非标准，与 nginx 一致)
+const StatusClientClosedRequest = 499
+
+// Code 创建一个表示 HTTP 状态码的错误码，
+// 通过 errors.WithCode 附加到错误上后 StatusCode 会直接使用该状态码
+func Code(status int) errors.Code {
+	return errors.IntCode(CodeDomain, status)
+}
+
+// StatusMapper 将错误映射为 HTTP 状态码，无法映射时返回 0
+type StatusMapper func(err error) int
+
+var mappers []StatusMapper
+
+// RegisterStatusMapper 注册状态码映射函数，后注册的先执行。
+// 应当在 init 中注册
+func RegisterStatusMapper(mapper StatusMapper) {
+	if mapper != nil {
+		mappers = append(mappers, mapper)
+	}
+}
+
+// RegisterStatus 注册状态码映射规则: errors.Is(err, target) 时映射为 status
+func RegisterStatus(target error, status int) {
+	RegisterStatusMapper(func(err error) int {
+		if errors.Is(err, target) {
+			return status
+		}
+		return 0
+	})
+}
+
+var kindStatus = map[errors.Kind]int{
+	errors.KindInvalid:         http.StatusBadRequest,
+	errors.KindNotFound:        http.StatusNotFound,
+	errors.KindExist:           http.StatusConflict,
+	errors.KindPermission:      http.StatusForbidden,
+	errors.KindUnauthenticated: http.StatusUnauthorized,
+	errors.KindConflict:        http.StatusConflict,
+	errors.KindUnavailable:     http.StatusServiceUnavailable,
+	errors.KindTimeout:         http.StatusGatewayTimeout,
+	errors.KindCanceled:        StatusClientClosedRequest,
+	errors.KindUnimplemented:   http.StatusNotImplemented,
+	errors.KindInternal:        http.StatusInternalServerError,
+}
+
+// StatusCode 获取错误对应的 HTTP 状态码，依次使用:
+//   - 注册的映射函数(见 RegisterStatusMapper, RegisterStatus)
+//   - 域为 CodeDomain 的错误码(见 Code)
+//   - 错误的类别(见 errors.KindOf)
+//
+// 都无法映射时返回 500。err 为 nil 时返回 200
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	for i := len(mappers) - 1; i >= 0; i-- {
+		if status := mappers[i](err); status != 0 {
+			return status
+		}
+	}
+	if code, ok := errors.GetCode(err); ok && code.Domain == CodeDomain {
+		if status, ok := code.Int(); ok && status >= 400 && status <= 599 {
+			return status
+		}
+	}
+	if status, ok := kindStatus[errors.KindOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Problem RFC 7807 错误响应
+type Problem struct {
+	// Type 错误类型的 URI，默认为 about:blank
+	Type string `json:"type"`
+	// Title 状态码对应的简短描述
+	Title string `json:"title"`
+	// Status HTTP 状态码
+	Status int `json:"status"`
+	// Detail 脱敏后的错误信息(见 errors.Redact)，5xx 错误不输出
+	Detail string `json:"detail,omitempty"`
+	// Instance 出错的请求路径
+	Instance string `json:"instance,omitempty"`
+	// Code 错误码(扩展字段)
+	Code string `json:"code,omitempty"`
+	// Hints 面向最终用户的提示(扩展字段)，见 errors.WithHint
+	Hints []string `json:"hints,omitempty"`
+}
+
+// NewProblem 根据错误构造 RFC 7807 错误响应。
+// 只使用错误码、提示及脱敏后的错误信息，不会泄露 %+v 格式输出的详细信息、堆栈
+func NewProblem(r *http.Request, err error) *Problem {
+	status := StatusCode(err)
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Hints:  errors.GetAllHints(err),
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(http.StatusInternalServerError)
+	}
+	if status < http.StatusInternalServerError {
+		p.Detail = errors.Redact(err)
+	}
+	if r != nil && r.URL != nil {
+		p.Instance = r.URL.Path
+	}
+	if code, ok := errors.GetCode(err); ok && code.Domain != CodeDomain {
+		p.Code = code.String()
+	}
+	return p
+}
+
+// WriteError 以 `application/problem+json` 格式输出错误
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	p := NewProblem(r, err)
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Logger 记录中间件恢复的错误
+type Logger func(r *http.Request, err error)
+
+// Recover 恢复 next 中的 panic:
+// 将 panic 转换为带堆栈的错误(见 errors.FromPanic)，使用 logger 记录完整的错误树，
+// 并以 500 状态码输出错误；panic 前已经开始输出响应时只记录错误。
+// logger 为 nil 时使用标准库 log 以 %+v 格式输出。
+// http.ErrAbortHandler 会被重新抛出
+func Recover(next http.Handler, logger Logger) http.Handler {
+	if logger == nil {
+		logger = func(r *http.Request, err error) {
+			log.Printf("%s %s: %+v", r.Method, r.URL.Path, err)
+		}
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w := &responseWriter{ResponseWriter: rw}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			err := errors.FromPanic(v)
+			logger(r, err)
+			if !w.wroteHeader { // 已经输出了部分响应 无法再输出错误
+				WriteError(w, r, errors.WithKind(err, errors.KindInternal))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// responseWriter 记录是否已经开始输出响应
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	// 1xx 信息性响应之后仍可以输出最终的响应(101 除外)
+	if status >= 200 || status == http.StatusSwitchingProtocols {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+// 接管连接(如 WebSocket 升级)后视为已经开始输出响应
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	w.wroteHeader = true
+	return h.Hijack()
+}
+
+// Unwrap 用于 http.ResponseController 获取原始的 http.ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,157 @@
+package errhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+	"code.gopub.tech/errors/errhttp"
+)
+
+var errQuota = errors.New("quota exceeded")
+
+func init() {
+	errhttp.RegisterStatus(errQuota, http.StatusTooManyRequests)
+}
+
+func TestStatusCode(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusOK},
+		{io.EOF, http.StatusInternalServerError},
+		{errors.Wrap(fs.ErrNotExist, "open"), http.StatusNotFound},
+		{errors.WithKind(io.EOF, errors.KindInvalid), http.StatusBadRequest},
+		{errors.WithStack(context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{errors.WithCode(errors.WithKind(io.EOF, errors.KindInvalid), errhttp.Code(http.StatusTeapot)), http.StatusTeapot},
+		{errors.WithCode(io.EOF, errors.IntCode("db", 404)), http.StatusInternalServerError},
+		{errors.Wrap(errQuota, "call"), http.StatusTooManyRequests},
+	} {
+		if got := errhttp.StatusCode(c.err); got != c.want {
+			t.Errorf("StatusCode(%v) = %d, want %d", c.err, got, c.want)
+		}
+	}
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) errhttp.Problem {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != errhttp.ContentType {
+		t.Errorf("Content-Type got %q", ct)
+	}
+	var p errhttp.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	return p
+}
+
+func TestWriteError(t *testing.T) {
+	err := errors.WithHint(
+		errors.WithCode(
+			errors.WithKind(errors.Errorf("user %d not found", 42), errors.KindNotFound),
+			errors.StringCode("user", "NotFound"),
+		),
+		"check the user id",
+	)
+	rec := httptest.NewRecorder()
+	errhttp.WriteError(rec, httptest.NewRequest("GET", "/users/42", nil), errors.Wrap(err, "get user"))
+	p := decodeProblem(t, rec)
+	want := errhttp.Problem{
+		Type:     "about:blank",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "get user: user ‹×› not found",
+		Instance: "/users/42",
+		Code:     "user:NotFound",
+		Hints:    []string{"check the user id"},
+	}
+	if rec.Code != http.StatusNotFound || fmt.Sprint(p) != fmt.Sprint(want) {
+		t.Errorf("got %d %+v", rec.Code, p)
+	}
+
+	rec = httptest.NewRecorder()
+	errhttp.WriteError(rec, httptest.NewRequest("GET", "/", nil), errors.Errorf("db password=%s", "secret"))
+	if p := decodeProblem(t, rec); p.Status != 500 || p.Detail != "" || strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("5xx should not leak details: %s", rec.Body.String())
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var logged error
+	h := errhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["boom"]++
+	}), func(r *http.Request, err error) { logged = err })
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/panic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var p errhttp.Problem
+	json.NewDecoder(resp.Body).Decode(&p)
+	if resp.StatusCode != 500 || p.Instance != "/panic" || p.Detail != "" {
+		t.Errorf("got %d %+v", resp.StatusCode, p)
+	}
+	if !errors.IsPanic(logged) || !strings.Contains(fmt.Sprintf("%+v", logged), "errhttp_test.TestRecover.func1") {
+		t.Errorf("logged error should contain the panic stack: %+v", logged)
+	}
+
+	// 已经开始输出响应时只记录错误
+	logged = nil
+	rec := httptest.NewRecorder()
+	errhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("partial"))
+		panic("boom")
+	}), func(r *http.Request, err error) { logged = err }).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "partial" || !errors.IsPanic(logged) {
+		t.Errorf("partial response should not be modified: %d %q", rec.Code, rec.Body.String())
+	}
+
+	// 接管连接后只记录错误
+	hijacked := make(chan error, 1)
+	srv = httptest.NewServer(errhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("Recover should keep http.Hijacker")
+			return
+		}
+		conn, buf, err := h.Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+		buf.Flush()
+		conn.Close()
+		panic("boom")
+	}), func(r *http.Request, err error) { hijacked <- err }))
+	defer srv.Close()
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != "ok" || !errors.IsPanic(<-hijacked) {
+		t.Errorf("hijacked response should not be modified: %d %q", resp.StatusCode, body)
+	}
+
+	// 没有 panic 时不影响响应
+	rec = httptest.NewRecorder()
+	errhttp.Recover(http.NotFoundHandler(), nil).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got %d", rec.Code)
+	}
+}
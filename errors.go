@@ -1,7 +1,6 @@
 package errors
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 )
@@ -12,13 +11,19 @@ func New(msg string) error {
 	// 打印时直接输出 msg + stacktrace
 	// 而不用先输出 stacktrace 再输出 Next error
 	return &fundamental{
-		string: msg,
-		stack:  callers(),
+		string:     msg,
+		stack:      callers(),
+		redactable: safeRedactable(msg),
 	}
 }
 
 // Errorf 按指定格式新建一个错误实例，带堆栈
 func Errorf(format string, args ...any) error {
+	return errorf(callers(), format, args...)
+}
+
+// errorf 按指定格式新建一个错误实例，附加堆栈 st
+func errorf(st *stack, format string, args ...any) error {
 	format = formatPlusW(format) // 绕过编译检查(只有内置函数可以用 %w)
 	var hasRef bool
 	for _, arg := range args {
@@ -29,8 +34,10 @@ func Errorf(format string, args ...any) error {
 	}
 	if !hasRef { // 参数无 error 直接格式化即可
 		return &fundamental{
-			string: fmt.Sprintf(format, args...),
-			stack:  callers(),
+			string:     fmt.Sprintf(format, args...),
+			stack:      st,
+			redactable: redactableSprintf(format, args...),
+			format:     format,
 		}
 	}
 
@@ -62,6 +69,8 @@ func Errorf(format string, args ...any) error {
 
 	var err error
 	errMsg := fmtErr.Error()
+	// 脱敏形式中 error 参数视为不安全 脱敏输出时会单独输出其脱敏后的消息
+	redactMsg := redactableSprintf(strings.ReplaceAll(format, "%w", "%v"), args...)
 	if hasWrap { // 通过 %w 包装了
 		causeMsg := wrapedErr.Error()
 		if strings.HasSuffix(errMsg, causeMsg) {
@@ -72,20 +81,29 @@ func Errorf(format string, args ...any) error {
 			prefix = strings.TrimSuffix(prefix, " ")
 			prefix = strings.TrimSuffix(prefix, ":")
 			err = &withPrefix{
-				error:  wrapedErr,
-				string: prefix,
+				error:      wrapedErr,
+				string:     prefix,
+				redactable: redactablePrefix(redactMsg, causeMsg, prefix),
+				format:     format,
 			}
 		} else {
 			// 如果 err 和 cause 不是添加前缀的关系
 			// 在输出详细模式时，两者都会输出
 			// prefix: %w, %w
 			err = &withNewMessage{
-				cause:   wrapedErr,      // %w\n%w
-				message: fmtErr.Error(), // prefix: %w, %w
+				cause:      wrapedErr,      // %w\n%w
+				message:    fmtErr.Error(), // prefix: %w, %w
+				redactable: redactMsg,
+				format:     format,
 			}
 		}
-	} else { // 没有包装任何错误
-		err = errors.New(errMsg)
+	} else { // 没有包装任何错误 没被 wrap 的错误当做次要错误记录一下
+		return WithSecondary(&fundamental{
+			string:     errMsg,
+			stack:      st,
+			redactable: redactMsg,
+			format:     format,
+		}, join(errRefs...))
 	}
 
 	if len(errRefs) > 0 { // 没被 wrap 的错误当做次要错误记录一下
@@ -94,7 +112,7 @@ func Errorf(format string, args ...any) error {
 
 	return &withStack{
 		error: err,
-		stack: callers(),
+		stack: st,
 	}
 }
 
@@ -115,7 +133,11 @@ func WithMessage(err error, msg string) error {
 	if err == nil {
 		return nil
 	}
-	return &withPrefix{error: err, string: msg}
+	return &withPrefix{
+		error:      err,
+		string:     msg,
+		redactable: safeRedactable(msg),
+	}
 }
 
 // WithMessagef 给错误添加一个指定格式的前缀注解信息
@@ -124,8 +146,10 @@ func WithMessagef(err error, format string, args ...any) error {
 		return nil
 	}
 	return &withPrefix{
-		error:  err,
-		string: fmt.Sprintf(format, args...),
+		error:      err,
+		string:     fmt.Sprintf(format, args...),
+		redactable: redactableSprintf(format, args...),
+		format:     format,
 	}
 }
 
@@ -194,6 +218,18 @@ func WithStack(err error) error {
 	}
 }
 
+// WithStackDepth 为错误添加堆栈信息，最多记录 depth 帧，
+// depth <= 0 时使用 MaxStackDepth
+func WithStackDepth(err error, depth int) error {
+	if err == nil {
+		return nil
+	}
+	return &withStack{
+		error: err,
+		stack: callersDepth(depth),
+	}
+}
+
 // Join 聚合多个错误
 func Join(errs ...error) error {
 	err := join(errs...)
@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Field 附加到错误上的键值对
+type Field struct {
+	Key   string
+	Value any
+}
+
+// badKey 参数中缺少键时使用的键 与 log/slog 一致
+const badKey = "!BADKEY"
+
+// attrToField 将 slog.Attr 转换为 Field (Go 1.21 起可用)
+var attrToField func(arg any) (Field, bool)
+
+// argsToFields 按 log/slog 的规则解析参数:
+// 字符串后跟一个值为一个键值对，Field (及 Go 1.21 起的 slog.Attr) 为一个键值对，
+// 其他值使用 !BADKEY 作为键
+func argsToFields(args []any) []Field {
+	fields := make([]Field, 0, len(args))
+	for len(args) > 0 {
+		switch arg := args[0].(type) {
+		case string:
+			if len(args) == 1 {
+				fields = append(fields, Field{Key: badKey, Value: arg})
+				args = nil
+				continue
+			}
+			fields = append(fields, Field{Key: arg, Value: args[1]})
+			args = args[2:]
+			continue
+		case Field:
+			fields = append(fields, arg)
+		default:
+			if f, ok := convertAttr(arg); ok {
+				fields = append(fields, f)
+			} else {
+				fields = append(fields, Field{Key: badKey, Value: arg})
+			}
+		}
+		args = args[1:]
+	}
+	return fields
+}
+
+func convertAttr(arg any) (Field, bool) {
+	if attrToField == nil {
+		return Field{}, false
+	}
+	return attrToField(arg)
+}
+
+// WithFields 给错误附加键值对，参数形式同 log/slog:
+//
+//	errors.WithFields(err, "user", 42, "order", id)
+//
+// 键值对不会改变 Error() 的内容，仅在使用 %+v 格式化动词时才会打印，
+// 可通过 Fields 获取整棵错误树中的所有键值对
+func WithFields(err error, args ...any) error {
+	if err == nil || len(args) == 0 {
+		return err
+	}
+	return &withFields{cause: err, fields: argsToFields(args)}
+}
+
+// Fields 获取整棵错误树(包括次要错误、聚合的多个错误)中的所有键值对，
+// 由外到内排列，键相同时外层的值覆盖内层的值
+func Fields(err error) []Field {
+	var (
+		result []Field
+		seen   = map[string]bool{}
+	)
+	Walk(err, func(node Node) WalkAction {
+		if e, ok := node.Err.(*withFields); ok {
+			for _, f := range e.fields {
+				if !seen[f.Key] {
+					seen[f.Key] = true
+					result = append(result, f)
+				}
+			}
+		}
+		return WalkContinue
+	})
+	return result
+}
+
+var _ error = (*withFields)(nil)
+var _ ErrorPrinter = (*withFields)(nil)
+var _ fmt.Formatter = (*withFields)(nil)
+
+type withFields struct {
+	cause  error
+	fields []Field
+}
+
+func (e *withFields) Error() string                 { return e.cause.Error() }
+func (e *withFields) Cause() error                  { return e.cause }
+func (e *withFields) Unwrap() error                 { return e.cause }
+func (e *withFields) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// PrintError implements ErrorPrinter.
+func (e *withFields) PrintError(p Printer) (next error) {
+	p.PrintDetailf("fields:")
+	for _, f := range e.fields {
+		// 键视为安全 值视为不安全
+		p.PrintDetailf(" %v=%v", Safe(f.Key), f.Value)
+	}
+	return e.cause
+}
+
+// encodedField 键值对编码时的载荷
+type encodedField struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+func init() {
+	fieldsKey := GetTypeKey(&withFields{})
+	RegisterWrapperEncoder(fieldsKey, func(err error) (string, json.RawMessage) {
+		var fields []encodedField
+		for _, f := range err.(*withFields).fields {
+			fields = append(fields, encodedField{Key: f.Key, Value: f.Value})
+		}
+		b, e := json.Marshal(fields)
+		if e != nil { // 值无法序列化时 使用其字符串形式
+			for i, f := range fields {
+				fields[i].Value = fmt.Sprint(f.Value)
+			}
+			b, _ = json.Marshal(fields)
+		}
+		return "", b
+	})
+	RegisterWrapperDecoder(fieldsKey, func(cause error, _ string, payload json.RawMessage) error {
+		var fields []encodedField
+		json.Unmarshal(payload, &fields)
+		e := &withFields{cause: cause}
+		for _, f := range fields {
+			e.fields = append(e.fields, Field{Key: f.Key, Value: f.Value})
+		}
+		return e
+	})
+}
@@ -0,0 +1,14 @@
+//go:build go1.21
+
+package errors
+
+import "log/slog"
+
+func init() {
+	attrToField = func(arg any) (Field, bool) {
+		if a, ok := arg.(slog.Attr); ok {
+			return Field{Key: a.Key, Value: a.Value.Any()}, true
+		}
+		return Field{}, false
+	}
+}
@@ -0,0 +1,19 @@
+//go:build go1.21
+
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestWithFieldsAttr(t *testing.T) {
+	err := errors.WithFields(io.EOF, slog.Int("user", 42), slog.String("order", "o-1"))
+	if got := fmt.Sprint(errors.Fields(err)); got != "[{user 42} {order o-1}]" {
+		t.Errorf("Fields got %s", got)
+	}
+}
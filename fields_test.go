@@ -0,0 +1,41 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestWithFields(t *testing.T) {
+	if errors.WithFields(nil, "k", 1) != nil || errors.WithFields(io.EOF) != io.EOF {
+		t.Errorf("WithFields with nil error or no args")
+	}
+	inner := errors.WithFields(io.EOF, "user", 42, "order", "o-1")
+	err := errors.WithFields(errors.Wrap(inner, "read"), errors.Field{Key: "user", Value: 7}, "dangling")
+	if err.Error() != "read: EOF" {
+		t.Errorf("WithFields should not change message, got %q", err.Error())
+	}
+	got := fmt.Sprint(errors.Fields(err))
+	if got != "[{user 7} {!BADKEY dangling} {order o-1}]" {
+		t.Errorf("Fields got %s", got)
+	}
+	s := fmt.Sprintf("%+v", err)
+	t.Log(s)
+	if !strings.Contains(s, "fields: user=42 order=o-1") {
+		t.Errorf("%%+v should show fields")
+	}
+	if r := errors.Redact(errors.WithSecondary(errors.New("x"), err)); r != "x" {
+		t.Errorf("Redact got %q", r)
+	}
+	if s := fmt.Sprintf("%+v", errors.Redacted(err)); !strings.Contains(s, "fields: user=‹×› !BADKEY=‹×›") {
+		t.Errorf("field values should be redacted: %s", s)
+	}
+
+	decoded := roundTrip(t, errors.WithSecondary(errors.New("x"), err))
+	if got := fmt.Sprint(errors.Fields(decoded)); got != "[{user 7} {!BADKEY dangling} {order o-1}]" {
+		t.Errorf("Fields after decoding got %s", got)
+	}
+}
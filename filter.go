@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+)
+
+// FrameFilter 堆栈帧过滤器，返回 false 表示在输出时隐藏该帧
+type FrameFilter func(f StackFrame) bool
+
+type frameFilterHolder struct{ filter FrameFilter }
+
+var frameFilter atomic.Value // frameFilterHolder
+
+// SetFrameFilter 设置输出堆栈时使用的过滤器，传入 nil 表示不过滤。
+// 对 %+v、StackDetail、Report、JSON 均生效，被隐藏的帧数会在输出中注明
+func SetFrameFilter(filter FrameFilter) {
+	frameFilter.Store(frameFilterHolder{filter: filter})
+}
+
+// GetFrameFilter 获取输出堆栈时使用的过滤器
+func GetFrameFilter() FrameFilter {
+	holder, _ := frameFilter.Load().(frameFilterHolder)
+	return holder.filter
+}
+
+// HideRuntime 隐藏 runtime 包中的帧，如 `runtime.goexit`, `runtime.main`
+func HideRuntime(f StackFrame) bool {
+	return framePackage(f) != "runtime"
+}
+
+// HideTesting 隐藏 testing 包中的帧，如 `testing.tRunner`
+func HideTesting(f StackFrame) bool {
+	return framePackage(f) != "testing"
+}
+
+// OnlyModule 仅保留指定模块(或包路径前缀)中的帧
+func OnlyModule(paths ...string) FrameFilter {
+	return func(f StackFrame) bool {
+		pkg := framePackage(f)
+		for _, path := range paths {
+			if pkg == path || strings.HasPrefix(pkg, path+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OnlyMainModule 仅保留主模块中的帧，
+// 主模块通过 debug.ReadBuildInfo 获取，获取不到时不过滤
+func OnlyMainModule() FrameFilter {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Path != "" {
+		return OnlyModule(bi.Main.Path)
+	}
+	return func(StackFrame) bool { return true }
+}
+
+// AllOf 组合多个过滤器，所有过滤器都保留的帧才会保留
+func AllOf(filters ...FrameFilter) FrameFilter {
+	return func(f StackFrame) bool {
+		for _, filter := range filters {
+			if filter != nil && !filter(f) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// framePackage 帧所在的包 外部测试包视为被测试的包
+func framePackage(f StackFrame) string {
+	return strings.TrimSuffix(funcPackage(f.Function), "_test")
+}
+
+// filterFrames 按当前的过滤器过滤堆栈帧 返回保留的帧及被隐藏的帧数
+func filterFrames(frames []StackFrame) (kept []StackFrame, hidden int) {
+	filter := GetFrameFilter()
+	if filter == nil {
+		return frames, 0
+	}
+	kept = make([]StackFrame, 0, len(frames))
+	for _, f := range frames {
+		if filter(f) {
+			kept = append(kept, f)
+		} else {
+			hidden++
+		}
+	}
+	return kept, hidden
+}
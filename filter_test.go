@@ -0,0 +1,47 @@
+package errors_test
+
+import (
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestFrameFilter(t *testing.T) {
+	defer errors.SetFrameFilter(nil)
+	err := errors.New("filter")
+	full := errors.NewReport(err)
+
+	errors.SetFrameFilter(errors.AllOf(errors.HideRuntime, errors.HideTesting))
+	detail := errors.Detail(err)
+	t.Logf("%s", detail)
+	if strings.Contains(detail, "testing.tRunner") || strings.Contains(detail, "runtime.goexit") {
+		t.Errorf("runtime/testing frames should be hidden")
+	}
+	if !strings.Contains(detail, "errors_test.TestFrameFilter") ||
+		!strings.Contains(detail, "[...2 frames hidden...]") {
+		t.Errorf("hidden marker missing")
+	}
+	r := errors.NewReport(err)
+	if len(r.Stack) != 1 || r.StackHidden != len(full.Stack)-1 {
+		t.Errorf("want 1 frame and %d hidden, got %d, %d", len(full.Stack)-1, len(r.Stack), r.StackHidden)
+	}
+
+	errors.SetFrameFilter(errors.OnlyModule("code.gopub.tech/errors"))
+	if r := errors.NewReport(err); len(r.Stack) != 1 || r.Stack[0].Function != full.Stack[0].Function {
+		t.Errorf("OnlyModule should keep test frames, got %+v", r.Stack)
+	}
+	errors.SetFrameFilter(errors.OnlyModule("example.com"))
+	if r := errors.NewReport(err); len(r.Stack) != 0 || r.StackHidden != len(full.Stack) {
+		t.Errorf("OnlyModule should hide all frames, got %+v", r.Stack)
+	}
+	errors.SetFrameFilter(errors.OnlyMainModule())
+	if r := errors.NewReport(err); r.Stack[0].Function != full.Stack[0].Function {
+		t.Errorf("OnlyMainModule should keep test frames, got %+v", r.Stack)
+	}
+
+	errors.SetFrameFilter(nil)
+	if errors.GetFrameFilter() != nil || strings.Contains(errors.Detail(err), "hidden") {
+		t.Errorf("nil filter should not hide frames")
+	}
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strconv"
+)
+
+// Fingerprint 计算错误的指纹，用于对错误去重、分组。
+// 指纹由以下内容计算得出，与消息中的变量(如 ID)无关:
+//   - 错误链中每个错误的类型
+//   - 本包创建的错误的消息模板: Errorf, Wrapf, WithMessagef 使用格式字符串，
+//     New, Wrap, WithMessage 使用消息本身；其他类型的错误只使用类型，不使用消息
+//   - 错误的创建位置，即最内层带堆栈的错误的堆栈第一帧的函数名及行号(不含文件路径)；
+//     调用方的帧不参与计算，从不同调用方到达同一位置的错误指纹相同
+//
+// 同一份代码多次运行、多次编译得到的指纹相同。
+// WithSecondary 附加的次要错误不参与计算。err 为 nil 时返回空字符串
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	h := sha256.New()
+	var origin []StackFrame // 最内层的堆栈
+	Walk(err, func(node Node) WalkAction {
+		if node.Edge == EdgeSecondary {
+			return WalkSkip
+		}
+		io.WriteString(h, strconv.Itoa(node.Depth))
+		io.WriteString(h, GetTypeKey(node.Err))
+		if tmpl, ok := messageTemplate(node.Err); ok {
+			io.WriteString(h, "\x00")
+			io.WriteString(h, tmpl)
+		}
+		io.WriteString(h, "\n")
+		if st, ok := GetStackTrace(node.Err); ok {
+			origin = symbolize(st)
+		} else if fp, ok := node.Err.(stackFramesProvider); ok {
+			origin = fp.StackFrames()
+		}
+		return WalkContinue
+	})
+	if len(origin) > 0 {
+		f := origin[0]
+		io.WriteString(h, f.Function)
+		io.WriteString(h, ":")
+		io.WriteString(h, strconv.Itoa(f.Line))
+		io.WriteString(h, "\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// messageTemplate 获取本包创建的错误的消息模板
+func messageTemplate(err error) (string, bool) {
+	switch e := err.(type) {
+	case *fundamental:
+		if e.format != "" {
+			return e.format, true
+		}
+		return e.string, true
+	case *withPrefix:
+		if e.format != "" {
+			return e.format, true
+		}
+		return e.string, true
+	case *withNewMessage:
+		if e.format != "" {
+			return e.format, true
+		}
+		return e.message, true
+	}
+	return "", false
+}
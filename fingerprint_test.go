@@ -0,0 +1,59 @@
+package errors_test
+
+import (
+	"io"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func loadUser(id int) error {
+	return errors.Wrapf(errors.Errorf("user %d not found", id), "load user %d", id)
+}
+
+func loadOrder(id string) error {
+	return errors.Errorf("load order %s: %w", id, io.ErrUnexpectedEOF)
+}
+
+func loadUserFromAPI() error { return loadUser(1) }
+
+func loadUserFromJob() error { return loadUser(2) }
+
+func TestFingerprint(t *testing.T) {
+	if errors.Fingerprint(nil) != "" {
+		t.Errorf("Fingerprint(nil) want empty")
+	}
+	var users, orders []string
+	for i := 0; i < 2; i++ {
+		users = append(users, errors.Fingerprint(loadUser(i)))
+		orders = append(orders, errors.Fingerprint(loadOrder(string(rune('a'+i)))))
+	}
+	t.Logf("fingerprint: %s", users[0])
+	if len(users[0]) != 16 {
+		t.Errorf("unexpected fingerprint %q", users[0])
+	}
+	if users[0] != users[1] || orders[0] != orders[1] {
+		t.Errorf("variable data should not affect fingerprint")
+	}
+	if users[0] == orders[0] {
+		t.Errorf("different failures should have different fingerprints")
+	}
+	// 调用方不参与计算
+	if errors.Fingerprint(loadUserFromAPI()) != errors.Fingerprint(loadUserFromJob()) ||
+		errors.Fingerprint(loadUserFromAPI()) != users[0] {
+		t.Errorf("the same site reached from different callers should have the same fingerprint")
+	}
+	// 次要错误不参与计算
+	err := loadUser(3)
+	if errors.Fingerprint(errors.WithSecondary(err, io.EOF)) != errors.Fingerprint(errors.WithSecondary(err, loadOrder("c"))) {
+		t.Errorf("secondary errors should not affect fingerprint")
+	}
+	// 同一位置 不同的消息模板
+	var errs []error
+	for _, msg := range []string{"a", "b"} {
+		errs = append(errs, errors.New(msg))
+	}
+	if errors.Fingerprint(errs[0]) == errors.Fingerprint(errs[1]) {
+		t.Errorf("different messages should have different fingerprints")
+	}
+}
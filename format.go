@@ -24,7 +24,7 @@ func Formattable(err error) FormattableError {
 	if err == nil {
 		return nil
 	}
-	return &errorFormatter{err}
+	return &errorFormatter{error: err}
 }
 
 // Detail 先将 err 包装为 Formattable 再使用 %+v 格式化为字符串
@@ -44,7 +44,12 @@ func Detail(err error) string {
 //
 // 其他情况，直接打印 Error() 文本。
 func FormatError(err error, s fmt.State, verb rune) {
-	p := state{State: s}
+	formatError(err, s, verb, false)
+}
+
+// formatError 格式化错误 redact 为 true 时脱敏输出
+func formatError(err error, s fmt.State, verb rune, redact bool) {
+	p := state{State: s, redact: redact}
 	switch {
 	case verb == 'v' && s.Flag('+') && !s.Flag('#'):
 		// 用 %+v 格式将每个错误输出到 p.buf
@@ -55,7 +60,7 @@ func FormatError(err error, s fmt.State, verb rune) {
 		// 得到了输出字符串，再看是否有宽度精度这些要求
 		p.finishDisplay(verb)
 
-	case verb == 'v' && s.Flag('#'):
+	case verb == 'v' && s.Flag('#') && !redact:
 		// %#v 语意为`输出 Go 语言表示`
 		if es, ok := err.(fmt.GoStringer); ok {
 			io.WriteString(&p.finalBuf, es.GoString())
@@ -64,8 +69,7 @@ func FormatError(err error, s fmt.State, verb rune) {
 		}
 		p.finishDisplay(verb)
 
-	case verb == 's' ||
-		(verb == 'v' && !s.Flag('#')) ||
+	case verb == 's' || verb == 'v' ||
 		(verb == 'x' || verb == 'X' || verb == 'q'):
 		// 不需要详情
 		p.entry = p.buildTree(err, false)
@@ -103,8 +107,9 @@ func (s *state) buildTree(err error, withDetail bool) *formatEntry {
 	// 包装错误的 simple 缓冲区是 `cause1\ncause2`，
 	// 应该省略 cause 的 simple 输出
 	var ignoreCause bool
-	switch v := err.(type) {
-	case ErrorPrinter:
+	v, isPrinter := err.(ErrorPrinter)
+	switch {
+	case isPrinter:
 		if e := v.PrintError((*printer)(s)); e == nil {
 			// 返回的 next error 为 nil 代表需要忽略 cause
 			ignoreCause = true
@@ -117,6 +122,11 @@ func (s *state) buildTree(err error, withDetail bool) *formatEntry {
 	entry.ignoreCause = ignoreCause
 	if st, ok := GetStackTrace(err); ok {
 		entry.stackTrace = st
+	} else if fp, ok := err.(stackFramesProvider); ok {
+		entry.stackFrames = fp.StackFrames()
+	}
+	if t, ok := err.(stackTruncation); ok {
+		entry.truncatedFrames = t.framesTruncated()
 	}
 
 	if cause := UnwrapOnce(err); cause != nil {
@@ -140,6 +150,17 @@ func (s *state) buildTree(err error, withDetail bool) *formatEntry {
 		// 输出时直接按顺序从上往下
 		entry.wraps = append(entry.wraps, wraps[i])
 	}
+	if s.redact && count > 0 && !isPrinter {
+		// 脱敏时 包装多个错误的消息由各个子错误脱敏后的消息拼接而成
+		var b []byte
+		for i, child := range entry.wraps {
+			if i > 0 {
+				b = append(b, '\n')
+			}
+			b = append(b, child.simpleString()...)
+		}
+		entry.simple = b
+	}
 
 	if len(entry.stackTrace) > 0 { // 重复堆栈优化输出
 		last := entry.stackTrace
@@ -163,14 +184,23 @@ func (s *state) formatDirect(err error) (ignoreCause bool) {
 	} else {
 		pref = err.Error()
 	}
-	if len(pref) > 0 {
-		s.Write([]byte(pref))
-	}
-	if causes := UnwrapMulti(err); len(causes) > 0 {
+	causes := UnwrapMulti(err)
+	if len(causes) > 0 {
 		// 如果包装了多个错误，则总是认为需要省略子错误
 		// 直接使用包装错误即可
 		ignoreCause = true
 	}
+	if s.redact {
+		// 外部错误类型的消息视为不安全
+		// 包装多个错误时 在 buildTree 中使用子错误脱敏后的消息
+		if len(pref) == 0 || len(causes) > 0 {
+			return
+		}
+		pref = redactedMarker
+	}
+	if len(pref) > 0 {
+		s.Write([]byte(pref))
+	}
 	return
 }
 
@@ -217,34 +247,36 @@ func (s *state) formatTree(err error) {
 	}
 }
 
+// simpleString 拼接本节点及其 cause 的简单消息，与 printErrorString 一致
+func (e *formatEntry) simpleString() []byte {
+	var b []byte
+	for entry := e; entry != nil; {
+		if len(b) > 0 && len(entry.simple) > 0 {
+			b = append(b, ": "...)
+		}
+		b = append(b, entry.simple...)
+		if entry.ignoreCause || len(entry.wraps) != 1 {
+			break
+		}
+		entry = entry.wraps[0]
+	}
+	return b
+}
+
 // printErrorString 输出简单模型的错误信息
 // 这里不直接使用 `err.Error()` 是因为，包装错误的 `Error()`
 // 通常会实现为 `return fmt.Sprint(err)` 从而调用到
 // `Format` 中的 `FormatError` 会触发递归。
 func (s *state) printErrorString() {
-	entry := s.entry
-	for entry != nil {
-		if s.finalBuf.Len() > 0 && len(entry.simple) > 0 {
-			s.finalBuf.WriteString(": ")
-		}
-		if len(entry.simple) > 0 {
-			s.finalBuf.Write(entry.simple)
-		}
-		if entry.ignoreCause {
-			break
-		}
-		if len(entry.wraps) == 1 {
-			entry = entry.wraps[0]
-		} else {
-			break
-		}
+	if s.entry != nil {
+		s.finalBuf.Write(s.entry.simpleString())
 	}
 }
 
 // print 格式化输出每个错误节点
 func (s *state) print(depth int, entry *formatEntry, prefix string, errType map[int]string) {
 	index := len(errType) + 1 // 计数
-	errType[index] = reflect.TypeOf(entry.err).String()
+	errType[index] = errorTypeName(entry.err)
 
 	if index == 1 { // 第一个特殊处理 不需要竖线开头
 		fmt.Fprintf(&s.finalBuf, "\n(1)")
@@ -325,12 +357,20 @@ func (s *state) printOne(prefix string, entry *formatEntry) {
 		}
 		sb.Write(entry.detail)
 	}
-	if entry.stackTrace != nil {
+	if entry.stackTrace != nil || entry.stackFrames != nil {
 		if sb.String() == "" {
 			sb.WriteString(" attached stack trace")
 		}
 		sb.WriteString("\n-- stack trace:")
-		sb.WriteString(StackDetail(entry.stackTrace))
+		truncated := entry.truncatedFrames
+		if entry.elidedStackTrace {
+			truncated = 0 // 截断的是末尾 已在下方重复的部分中注明
+		}
+		if entry.stackTrace != nil {
+			sb.WriteString(formatFrames(symbolize(entry.stackTrace), truncated))
+		} else {
+			sb.WriteString(formatFrames(entry.stackFrames, truncated))
+		}
 		if entry.elidedStackTrace {
 			sb.WriteString("\n[...repeated from below...]")
 		}
@@ -338,7 +378,7 @@ func (s *state) printOne(prefix string, entry *formatEntry) {
 
 	tmp := sb.String()
 	if tmp == "" {
-		s.finalBuf.WriteString(" " + reflect.TypeOf(entry.err).String())
+		s.finalBuf.WriteString(" " + errorTypeName(entry.err))
 		return
 	}
 
@@ -398,6 +438,8 @@ type state struct {
 	entry *formatEntry
 	// 记录最近一次的堆栈
 	lastStack []uintptr
+	// 脱敏输出
+	redact bool
 
 	// 下面的字段会在每轮递归时初始化
 
@@ -417,6 +459,10 @@ type formatEntry struct {
 	ignoreCause bool
 	// 堆栈
 	stackTrace []uintptr
+	// 已符号化的堆栈(解码得到的错误没有 pc)
+	stackFrames []StackFrame
+	// 超出最大深度而未被记录的帧数
+	truncatedFrames int
 	// 堆栈是否和其他 entry 有重复
 	elidedStackTrace bool
 	// 树形
@@ -465,10 +511,22 @@ func (s *printer) PrintDetailf(format string, args ...any) {
 	fmt.Fprintf(&s.detailBuf, format, args...)
 }
 
+// enhanceArgs 错误类型的参数包装为 errorFormatter 以支持智能打印。
+// 脱敏输出时，仅保留安全的内容，其他参数替换为 ‹×›
 func (s *printer) enhanceArgs(args []interface{}) {
 	for i := range args {
-		if err, ok := args[i].(error); ok {
-			args[i] = &errorFormatter{err}
+		switch v := args[i].(type) {
+		case error:
+			args[i] = &errorFormatter{error: v, redact: s.redact}
+		case message:
+			if s.redact {
+				args[i] = Safe(v.redactable.redact())
+			}
+		case SafeValue:
+		default:
+			if s.redact {
+				args[i] = Safe(redactedMarker)
+			}
 		}
 	}
 }
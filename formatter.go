@@ -33,10 +33,12 @@ var _ FormattableError = (*errorFormatter)(nil)
 
 type errorFormatter struct {
 	error
+	// 脱敏输出
+	redact bool
 }
 
 func (e *errorFormatter) Format(s fmt.State, verb rune) {
-	FormatError(e.error, s, verb) // 直接传包装的 error 不打印自身
+	formatError(e.error, s, verb, e.redact) // 直接传包装的 error 不打印自身
 }
 
 func (e *errorFormatter) Cause() error {
@@ -0,0 +1,138 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Frame 堆栈中的一帧，值为调用返回地址(程序计数器)。
+// 与 github.com/pkg/errors 的 Frame 兼容
+type Frame uintptr
+
+// frame 符号化结果 内联调用时取最内层的帧
+func (f Frame) frame() StackFrame { return symbolizePC(uintptr(f)) }
+
+// Function 函数全名 如 `code.gopub.tech/errors.New`
+func (f Frame) Function() string { return f.frame().Function }
+
+// File 源文件完整路径
+func (f Frame) File() string { return f.frame().File }
+
+// Line 源文件行号
+func (f Frame) Line() int { return f.frame().Line }
+
+// Package 函数所在包的导入路径 如 `code.gopub.tech/errors`
+func (f Frame) Package() string { return funcPackage(f.Function()) }
+
+// Format 格式化单个帧，支持的格式化动词与 pkg/errors 一致:
+//
+//	%s    源文件名
+//	%d    源文件行号
+//	%n    函数名(不含包名)
+//	%v    等价于 %s:%d
+//	%+s   函数全名与源文件完整路径，以 \n\t 分隔
+//	%+v   等价于 %+s:%d
+//
+// 输出的函数名、路径会按 StackStyle 改写
+func (f Frame) Format(s fmt.State, verb rune) {
+	sf := GetStackStyle().rewrite(f.frame())
+	switch verb {
+	case 's':
+		switch {
+		case s.Flag('+'):
+			io.WriteString(s, sf.Function)
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, sf.File)
+		default:
+			io.WriteString(s, path.Base(sf.File))
+		}
+	case 'd':
+		io.WriteString(s, strconv.Itoa(sf.Line))
+	case 'n':
+		io.WriteString(s, funcName(sf.Function))
+	case 'v':
+		f.Format(s, 's')
+		io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// MarshalText 格式化为 `函数全名 源文件:行号`
+func (f Frame) MarshalText() ([]byte, error) {
+	sf := GetStackStyle().rewrite(f.frame())
+	if sf.Function == "unknown" {
+		return []byte(sf.Function), nil
+	}
+	return []byte(fmt.Sprintf("%s %s:%d", sf.Function, sf.File, sf.Line)), nil
+}
+
+// StackTrace 从内到外的调用栈。
+// 与 github.com/pkg/errors 的 StackTrace 兼容
+type StackTrace []Frame
+
+// Format 格式化调用栈:
+//
+//	%s	列出每一帧的源文件名
+//	%v	列出每一帧的源文件名与行号
+//	%+v	列出每一帧的函数全名、源文件完整路径与行号
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			for _, f := range st {
+				io.WriteString(s, "\n")
+				f.Format(s, verb)
+			}
+		case s.Flag('#'):
+			fmt.Fprintf(s, "%#v", []Frame(st))
+		default:
+			st.formatSlice(s, verb)
+		}
+	case 's':
+		st.formatSlice(s, verb)
+	}
+}
+
+// formatSlice 格式化为 [a b c] 形式
+func (st StackTrace) formatSlice(s fmt.State, verb rune) {
+	io.WriteString(s, "[")
+	for i, f := range st {
+		if i > 0 {
+			io.WriteString(s, " ")
+		}
+		f.Format(s, verb)
+	}
+	io.WriteString(s, "]")
+}
+
+// pcs 转换为程序计数器切片
+func (st StackTrace) pcs() []uintptr {
+	pcs := make([]uintptr, len(st))
+	for i, f := range st {
+		pcs[i] = uintptr(f)
+	}
+	return pcs
+}
+
+// funcName 去掉函数全名中的包路径
+// `code.gopub.tech/errors.(*T).Method` => `(*T).Method`
+func funcName(name string) string {
+	i := strings.LastIndex(name, "/")
+	name = name[i+1:]
+	i = strings.Index(name, ".")
+	return name[i+1:]
+}
+
+// funcPackage 提取函数全名中的包路径
+// `code.gopub.tech/errors.(*T).Method` => `code.gopub.tech/errors`
+func funcPackage(name string) string {
+	i := strings.LastIndex(name, "/")
+	if j := strings.Index(name[i+1:], "."); j >= 0 {
+		return name[:i+1+j]
+	}
+	return name
+}
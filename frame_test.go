@@ -0,0 +1,63 @@
+package errors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+func TestFrame(t *testing.T) {
+	err := errors.New("frame") // line 16
+	st, ok := err.(stackTracer)
+	if !ok {
+		t.Fatalf("New should implement StackTrace() StackTrace")
+	}
+	f := st.StackTrace()[0]
+	if f.Function() != "code.gopub.tech/errors_test.TestFrame" {
+		t.Errorf("Function() got %s", f.Function())
+	}
+	if f.Package() != "code.gopub.tech/errors_test" {
+		t.Errorf("Package() got %s", f.Package())
+	}
+	if !strings.HasSuffix(f.File(), "/frame_test.go") || f.Line() != 16 {
+		t.Errorf("File()/Line() got %s:%d", f.File(), f.Line())
+	}
+	for format, want := range map[string]string{
+		"%s":  "frame_test.go",
+		"%d":  "16",
+		"%n":  "TestFrame",
+		"%v":  "frame_test.go:16",
+		"%+s": "code.gopub.tech/errors_test.TestFrame\n\t" + f.File(),
+		"%+v": "code.gopub.tech/errors_test.TestFrame\n\t" + f.File() + ":16",
+	} {
+		if got := fmt.Sprintf(format, f); got != want {
+			t.Errorf("%s: want %q, got %q", format, want, got)
+		}
+	}
+	if b, _ := f.MarshalText(); string(b) != fmt.Sprintf("%s %s:16", f.Function(), f.File()) {
+		t.Errorf("MarshalText got %s", b)
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	st := errors.New("stack").(stackTracer).StackTrace()
+	if s := fmt.Sprintf("%s", st[:1]); s != "[frame_test.go]" {
+		t.Errorf("%%s got %s", s)
+	}
+	if s := fmt.Sprintf("%v", st[:1]); !strings.HasPrefix(s, "[frame_test.go:") {
+		t.Errorf("%%v got %s", s)
+	}
+	if s := fmt.Sprintf("%+v", st[:1]); !strings.HasPrefix(s, "\ncode.gopub.tech/errors_test.TestStackTrace\n\t") {
+		t.Errorf("%%+v got %s", s)
+	}
+	pcs, ok := errors.GetStackTrace(errors.New("stack"))
+	if !ok || len(pcs) != len(st) {
+		t.Errorf("GetStackTrace got %v", pcs)
+	}
+}
@@ -3,11 +3,15 @@ package errors
 import "fmt"
 
 var _ error = (*fundamental)(nil)
+var _ ErrorPrinter = (*fundamental)(nil)
 var _ fmt.Formatter = (*fundamental)(nil)
 
 type fundamental struct {
 	string
 	*stack
+	redactable redactable
+	// format Errorf 的格式字符串
+	format string
 }
 
 func (e *fundamental) Error() string { return e.string }
@@ -15,3 +19,9 @@ func (e *fundamental) Error() string { return e.string }
 func (e *fundamental) Format(s fmt.State, verb rune) {
 	FormatError(e, s, verb)
 }
+
+// PrintError implements ErrorPrinter.
+func (e *fundamental) PrintError(p Printer) (next error) {
+	p.Print(message{plain: e.string, redactable: e.redactable})
+	return nil
+}
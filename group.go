@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Group 并发执行一组任务并收集所有任务的错误。
+// 与 x/sync/errgroup 不同，Wait 返回的是所有失败任务的错误的聚合，而非第一个错误；
+// 每个任务的错误以任务名称作为前缀，并附带调用 Go 处的堆栈，
+// 任务中的 panic 会被恢复并转换为错误。
+//
+// 零值可以直接使用，此时不限制并发数，也不会取消任何 context
+type Group struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	errs []error // 按调用 Go 的顺序记录 成功的任务为 nil
+}
+
+// GroupWithContext 创建一个 Group 及派生的 context，
+// 任一任务返回错误(或 panic)时，或 Wait 返回时，该 context 会被取消
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit 设置同时运行的任务数上限，n < 0 表示不限制。
+// 达到上限时 Go 会阻塞直到有任务结束。
+// 必须在调用 Go 之前设置
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(Errorf("errors: modify limit while %v goroutines in the group are still active", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go 在新的 goroutine 中运行名为 name 的任务
+func (g *Group) Go(name string, fn func() error) {
+	st := callers()
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.mu.Lock()
+	index := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		if err := runTask(fn); err != nil {
+			g.mu.Lock()
+			g.errs[index] = &withStack{
+				error: &withPrefix{
+					error:      err,
+					string:     name,
+					redactable: safeRedactable(name),
+				},
+				stack: st,
+			}
+			g.mu.Unlock()
+			if g.cancel != nil {
+				g.cancel()
+			}
+		}
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// runTask 运行任务 panic 时转换为错误
+func runTask(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
+
+// Wait 等待所有任务结束，返回所有失败任务的错误的聚合(按调用 Go 的顺序)，
+// 所有任务都成功时返回 nil
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.mu.Lock()
+	err := join(g.errs...)
+	g.mu.Unlock()
+	if err == nil {
+		return nil
+	}
+	return &withStack{
+		error: err,
+		stack: callers(),
+	}
+}
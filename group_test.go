@@ -0,0 +1,85 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.gopub.tech/errors"
+)
+
+func TestGroup(t *testing.T) {
+	var g errors.Group
+	if g.Wait() != nil {
+		t.Errorf("empty group should return nil")
+	}
+	g.Go("ok", func() error { return nil })
+	g.Go("read", func() error { return io.EOF })
+	g.Go("crash", func() error { panic("boom") })
+	err := g.Wait()
+	t.Logf("%+v", err)
+	if err.Error() != "read: EOF\ncrash: panic: boom" {
+		t.Errorf("Error() got %q", err.Error())
+	}
+	if !errors.IsPanic(err) {
+		t.Errorf("task panic should be recovered")
+	}
+	// 每个任务的错误都带有调用 Go 处的堆栈
+	tasks := errors.Unwrap(err).(interface{ Unwrap() []error }).Unwrap()
+	for _, task := range tasks {
+		frame := errors.NewReport(task).Stack[0]
+		if !strings.HasSuffix(frame.Function, "errors_test.TestGroup") {
+			t.Errorf("task stack should start at Go call site, got %s", frame.Function)
+		}
+	}
+}
+
+func TestGroupWithContext(t *testing.T) {
+	g, ctx := errors.GroupWithContext(context.Background())
+	g.Go("fail", func() error { return errors.New("fail") })
+	g.Go("wait", func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	err := g.Wait()
+	if err.Error() != "fail: fail\nwait: context canceled" {
+		t.Errorf("Error() got %q", err.Error())
+	}
+}
+
+func TestGroupSetLimit(t *testing.T) {
+	var (
+		g               errors.Group
+		running, maxRun int32
+	)
+	g.SetLimit(2)
+	for i := 0; i < 10; i++ {
+		i := i
+		g.Go(fmt.Sprintf("task-%d", i), func() error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRun)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRun, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			if i%3 == 0 {
+				return errors.Errorf("bad %d", i)
+			}
+			return nil
+		})
+	}
+	err := g.Wait()
+	if maxRun > 2 {
+		t.Errorf("limit exceeded: %d", maxRun)
+	}
+	if err.Error() != "task-0: bad 0\ntask-3: bad 3\ntask-6: bad 6\ntask-9: bad 9" {
+		t.Errorf("Error() got %q", err.Error())
+	}
+}
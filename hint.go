@@ -0,0 +1,171 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WithHint 给错误附加一条面向最终用户的提示，如“请检查网络后重试”。
+// 提示不会改变 Error() 的内容，仅在使用 %+v 格式化动词时才会打印，
+// 可通过 GetAllHints 获取整棵错误树中的所有提示
+func WithHint(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &withHint{
+		cause: err,
+		hint:  message{plain: msg, redactable: safeRedactable(msg)},
+	}
+}
+
+// WithHintf 给错误附加一条指定格式的面向最终用户的提示
+func WithHintf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return &withHint{
+		cause: err,
+		hint: message{
+			plain:      fmt.Sprintf(format, args...),
+			redactable: redactableSprintf(format, args...),
+		},
+	}
+}
+
+// WithDetail 给错误附加一条面向开发者的详细信息。
+// 详细信息不会改变 Error() 的内容，仅在使用 %+v 格式化动词时才会打印，
+// 可通过 GetAllDetails 获取整棵错误树中的所有详细信息
+func WithDetail(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &withDetail{
+		cause:  err,
+		detail: message{plain: msg, redactable: safeRedactable(msg)},
+	}
+}
+
+// WithDetailf 给错误附加一条指定格式的面向开发者的详细信息
+func WithDetailf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return &withDetail{
+		cause: err,
+		detail: message{
+			plain:      fmt.Sprintf(format, args...),
+			redactable: redactableSprintf(format, args...),
+		},
+	}
+}
+
+// GetAllHints 获取整棵错误树(包括次要错误、聚合的多个错误)中的所有提示，
+// 由外到内排列，重复的提示只保留一条
+func GetAllHints(err error) []string {
+	return collectMessages(err, func(err error) (message, bool) {
+		if e, ok := err.(*withHint); ok {
+			return e.hint, true
+		}
+		return message{}, false
+	})
+}
+
+// GetAllDetails 获取整棵错误树(包括次要错误、聚合的多个错误)中的所有详细信息，
+// 由外到内排列，重复的详细信息只保留一条
+func GetAllDetails(err error) []string {
+	return collectMessages(err, func(err error) (message, bool) {
+		if e, ok := err.(*withDetail); ok {
+			return e.detail, true
+		}
+		return message{}, false
+	})
+}
+
+func collectMessages(err error, get func(err error) (message, bool)) (result []string) {
+	seen := map[string]bool{}
+	Walk(err, func(node Node) WalkAction {
+		if msg, ok := get(node.Err); ok && !seen[msg.plain] {
+			seen[msg.plain] = true
+			result = append(result, msg.plain)
+		}
+		return WalkContinue
+	})
+	return
+}
+
+var _ error = (*withHint)(nil)
+var _ ErrorPrinter = (*withHint)(nil)
+var _ fmt.Formatter = (*withHint)(nil)
+
+type withHint struct {
+	cause error
+	hint  message
+}
+
+func (e *withHint) Error() string                 { return e.cause.Error() }
+func (e *withHint) Cause() error                  { return e.cause }
+func (e *withHint) Unwrap() error                 { return e.cause }
+func (e *withHint) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// PrintError implements ErrorPrinter.
+func (e *withHint) PrintError(p Printer) (next error) {
+	p.PrintDetailf("hint: %v", e.hint)
+	return e.cause
+}
+
+var _ error = (*withDetail)(nil)
+var _ ErrorPrinter = (*withDetail)(nil)
+var _ fmt.Formatter = (*withDetail)(nil)
+
+type withDetail struct {
+	cause  error
+	detail message
+}
+
+func (e *withDetail) Error() string                 { return e.cause.Error() }
+func (e *withDetail) Cause() error                  { return e.cause }
+func (e *withDetail) Unwrap() error                 { return e.cause }
+func (e *withDetail) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// PrintError implements ErrorPrinter.
+func (e *withDetail) PrintError(p Printer) (next error) {
+	p.PrintDetailf("detail: %v", e.detail)
+	return e.cause
+}
+
+// encodedMessage 附加信息编码时的载荷
+type encodedMessage struct {
+	Message    string `json:"message"`
+	Redactable string `json:"redactable,omitempty"`
+}
+
+func encodeMessage(m message) json.RawMessage {
+	b, _ := json.Marshal(encodedMessage{Message: m.plain, Redactable: string(m.redactable)})
+	return b
+}
+
+func decodeMessage(payload json.RawMessage) message {
+	var em encodedMessage
+	json.Unmarshal(payload, &em)
+	m := message{plain: em.Message, redactable: redactable(em.Redactable)}
+	if em.Redactable == "" {
+		m.redactable = unsafeRedactable(em.Message)
+	}
+	return m
+}
+
+func init() {
+	hintKey, detailKey := GetTypeKey(&withHint{}), GetTypeKey(&withDetail{})
+	RegisterWrapperEncoder(hintKey, func(err error) (string, json.RawMessage) {
+		return "", encodeMessage(err.(*withHint).hint)
+	})
+	RegisterWrapperDecoder(hintKey, func(cause error, _ string, payload json.RawMessage) error {
+		return &withHint{cause: cause, hint: decodeMessage(payload)}
+	})
+	RegisterWrapperEncoder(detailKey, func(err error) (string, json.RawMessage) {
+		return "", encodeMessage(err.(*withDetail).detail)
+	})
+	RegisterWrapperDecoder(detailKey, func(cause error, _ string, payload json.RawMessage) error {
+		return &withDetail{cause: cause, detail: decodeMessage(payload)}
+	})
+}
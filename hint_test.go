@@ -0,0 +1,56 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestHint(t *testing.T) {
+	if errors.WithHint(nil, "h") != nil || errors.WithHintf(nil, "h") != nil ||
+		errors.WithDetail(nil, "d") != nil || errors.WithDetailf(nil, "d") != nil {
+		t.Errorf("nil error should stay nil")
+	}
+	inner := errors.WithHint(errors.New("conn refused"), "check your network")
+	err := errors.WithDetailf(
+		errors.WithSecondary(
+			errors.Join(
+				errors.WithHintf(inner, "retry in %d seconds", 5),
+				errors.WithHint(errFmt, "check your network"),
+			),
+			errors.WithHint(errFmt, "contact support"),
+		),
+		"dial %s", "10.0.0.1:80",
+	)
+	if err.Error() != "conn refused\n"+errFmt.Error() {
+		t.Errorf("Error() should not contain hints, got %q", err.Error())
+	}
+	hints := errors.GetAllHints(err)
+	if want := []string{"retry in 5 seconds", "check your network", "contact support"}; fmt.Sprint(hints) != fmt.Sprint(want) {
+		t.Errorf("GetAllHints want %q, got %q", want, hints)
+	}
+	if details := errors.GetAllDetails(err); len(details) != 1 || details[0] != "dial 10.0.0.1:80" {
+		t.Errorf("GetAllDetails got %q", details)
+	}
+	detail := errors.Detail(err)
+	t.Logf("%s", detail)
+	for _, want := range []string{"hint: retry in 5 seconds", "detail: dial 10.0.0.1:80"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("%%+v should contain %q", want)
+		}
+	}
+	if s := fmt.Sprintf("%+v", errors.Redacted(err)); !strings.Contains(s, "detail: dial ‹×›") {
+		t.Errorf("detail should be redacted:\n%s", s)
+	}
+
+	b, _ := json.Marshal(errors.EncodeError(err))
+	var enc errors.EncodedError
+	json.Unmarshal(b, &enc)
+	decoded := errors.DecodeError(enc)
+	if fmt.Sprint(errors.GetAllHints(decoded)) != fmt.Sprint(hints) {
+		t.Errorf("hints lost after decoding: %q", errors.GetAllHints(decoded))
+	}
+}
@@ -0,0 +1,158 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Kind 错误的类别，用于在传输层等处按类别处理错误，
+// 而不必引入各个业务包中定义的哨兵错误
+type Kind uint8
+
+const (
+	// KindOther 未分类的错误
+	KindOther Kind = iota
+	// KindInvalid 参数无效
+	KindInvalid
+	// KindNotFound 资源不存在
+	KindNotFound
+	// KindExist 资源已存在
+	KindExist
+	// KindPermission 没有权限
+	KindPermission
+	// KindUnauthenticated 未认证
+	KindUnauthenticated
+	// KindConflict 与当前状态冲突 如并发修改
+	KindConflict
+	// KindUnavailable 服务暂时不可用
+	KindUnavailable
+	// KindTimeout 超时
+	KindTimeout
+	// KindCanceled 操作被取消
+	KindCanceled
+	// KindUnimplemented 功能未实现
+	KindUnimplemented
+	// KindInternal 内部错误
+	KindInternal
+)
+
+var kindNames = [...]string{
+	KindOther:           "other",
+	KindInvalid:         "invalid",
+	KindNotFound:        "not found",
+	KindExist:           "already exists",
+	KindPermission:      "permission denied",
+	KindUnauthenticated: "unauthenticated",
+	KindConflict:        "conflict",
+	KindUnavailable:     "unavailable",
+	KindTimeout:         "timeout",
+	KindCanceled:        "canceled",
+	KindUnimplemented:   "unimplemented",
+	KindInternal:        "internal",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// kindSentinels 标准库中的哨兵错误对应的类别
+var kindSentinels = []struct {
+	err  error
+	kind Kind
+}{
+	{fs.ErrNotExist, KindNotFound},
+	{fs.ErrExist, KindExist},
+	{fs.ErrPermission, KindPermission},
+	{fs.ErrInvalid, KindInvalid},
+	{context.DeadlineExceeded, KindTimeout},
+	{os.ErrDeadlineExceeded, KindTimeout},
+	{context.Canceled, KindCanceled},
+}
+
+// WithKind 给错误附加类别，不改变错误消息。
+// 类别在使用 %+v 格式化动词时打印，可通过 KindOf 获取
+func WithKind(err error, kind Kind) error {
+	if err == nil || kind == KindOther {
+		return err
+	}
+	return &withKind{cause: err, kind: kind}
+}
+
+// KindOf 获取错误的类别: 错误树(不包括次要错误)中最外层由 WithKind 附加的类别，
+// 或能识别的标准库哨兵错误(如 fs.ErrNotExist, context.DeadlineExceeded)对应的类别，
+// 都没有时返回 KindOther
+func KindOf(err error) Kind {
+	kind := KindOther
+	Walk(err, func(node Node) WalkAction {
+		if node.Edge == EdgeSecondary {
+			return WalkSkip
+		}
+		if e, ok := node.Err.(*withKind); ok {
+			kind = e.kind
+			return WalkStop
+		}
+		for _, s := range kindSentinels {
+			if isSentinel(node.Err, s.err) {
+				kind = s.kind
+				return WalkStop
+			}
+		}
+		return WalkContinue
+	})
+	return kind
+}
+
+// isSentinel 仅判断 err 本身(不展开)是否是 target
+func isSentinel(err, target error) bool {
+	if err == target {
+		return true
+	}
+	x, ok := err.(interface{ Is(error) bool })
+	return ok && x.Is(target)
+}
+
+var _ error = (*withKind)(nil)
+var _ ErrorPrinter = (*withKind)(nil)
+var _ fmt.Formatter = (*withKind)(nil)
+
+type withKind struct {
+	cause error
+	kind  Kind
+}
+
+func (e *withKind) Error() string                 { return e.cause.Error() }
+func (e *withKind) Cause() error                  { return e.cause }
+func (e *withKind) Unwrap() error                 { return e.cause }
+func (e *withKind) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// PrintError implements ErrorPrinter.
+func (e *withKind) PrintError(p Printer) (next error) {
+	p.PrintDetailf("kind: %v", Safe(e.kind))
+	return e.cause
+}
+
+func init() {
+	kindKey := GetTypeKey(&withKind{})
+	RegisterWrapperEncoder(kindKey, func(err error) (string, json.RawMessage) {
+		// 使用名称而非数值 增加类别后仍能正确解码
+		b, _ := json.Marshal(err.(*withKind).kind.String())
+		return "", b
+	})
+	RegisterWrapperDecoder(kindKey, func(cause error, _ string, payload json.RawMessage) error {
+		var name string
+		json.Unmarshal(payload, &name)
+		e := &withKind{cause: cause}
+		for k, n := range kindNames {
+			if n == name {
+				e.kind = Kind(k)
+			}
+		}
+		return e
+	})
+}
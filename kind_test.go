@@ -0,0 +1,61 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestKind(t *testing.T) {
+	if errors.KindNotFound.String() != "not found" || errors.Kind(200).String() != "Kind(200)" {
+		t.Errorf("Kind.String() got %s", errors.KindNotFound)
+	}
+	if errors.WithKind(io.EOF, errors.KindOther) != io.EOF || errors.KindOf(nil) != errors.KindOther {
+		t.Errorf("KindOther")
+	}
+	err := errors.Wrap(errors.WithKind(io.EOF, errors.KindConflict), "save")
+	if err.Error() != "save: EOF" {
+		t.Errorf("WithKind should not change message, got %q", err.Error())
+	}
+	if k := errors.KindOf(errors.WithKind(err, errors.KindInvalid)); k != errors.KindInvalid {
+		t.Errorf("KindOf should return the outermost kind, got %s", k)
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "kind: conflict") {
+		t.Errorf("%%+v should show the kind")
+	}
+	if k := errors.KindOf(roundTrip(t, err)); k != errors.KindConflict {
+		t.Errorf("kind should survive encoding, got %s", k)
+	}
+	if k := errors.KindOf(errors.WithSecondary(io.EOF, err)); k != errors.KindOther {
+		t.Errorf("KindOf should ignore secondary errors, got %s", k)
+	}
+}
+
+func TestKindOfSentinels(t *testing.T) {
+	_, errOpen := os.Open("/path/not/exist")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, c := range []struct {
+		err  error
+		want errors.Kind
+	}{
+		{errors.Wrap(errOpen, "open"), errors.KindNotFound},
+		{fmt.Errorf("mkdir: %w", fs.ErrExist), errors.KindExist},
+		{errors.Join(io.EOF, fs.ErrPermission), errors.KindPermission},
+		{errors.WithStack(context.DeadlineExceeded), errors.KindTimeout},
+		{errors.Wrap(ctx.Err(), "wait"), errors.KindCanceled},
+		{io.EOF, errors.KindOther},
+		// 显式的类别优先于内层的哨兵错误
+		{errors.WithKind(errOpen, errors.KindInternal), errors.KindInternal},
+	} {
+		if got := errors.KindOf(c.err); got != c.want {
+			t.Errorf("KindOf(%v) = %s, want %s", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// markIdentity 错误的标识: 类型 + 消息。
+// 与指针比较不同，编码解码后标识保持不变
+type markIdentity struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func identityOf(err error) markIdentity {
+	return markIdentity{Type: GetTypeKey(err), Message: err.Error()}
+}
+
+// Mark 给错误打上 reference 的标识(类型 + 消息)，不改变错误消息。
+// 之后 Is(err, reference) 与 IsMarked(err, reference) 均返回 true，
+// 即使 err 经过 EncodeError/DecodeError 跨进程传递也是如此
+func Mark(err, reference error) error {
+	if err == nil || reference == nil {
+		return err
+	}
+	return &withMark{cause: err, mark: identityOf(reference)}
+}
+
+// IsMarked 错误链中是否带有 reference 的标识:
+// 通过 Mark 标记过，或者是经过 DecodeError 还原的、类型与消息都与 reference 相同的错误。
+// 当 reference 本身是经过 DecodeError 还原的错误时，
+// 错误链中任何类型与消息都与之相同的本地错误都视为匹配。
+// 与 Is 一样只查找 `Unwrap() error`、`Cause() error`、`Unwrap() []error` 包装的错误
+func IsMarked(err, reference error) bool {
+	if err == nil || reference == nil {
+		return false
+	}
+	id := identityOf(reference)
+	_, refDecoded := reference.(originalTyper)
+	var found bool
+	Walk(err, func(node Node) WalkAction {
+		if node.Edge == EdgeSecondary {
+			return WalkSkip
+		}
+		if e, ok := node.Err.(*withMark); ok {
+			found = e.mark == id
+		} else if _, decoded := node.Err.(originalTyper); decoded || refDecoded {
+			// 解码得到的错误 与 reference 比较标识
+			found = identityOf(node.Err) == id
+		}
+		if found {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return found
+}
+
+// Is 判断错误链中是否有与 target 相同的错误。
+// 除标准库 errors.Is 的规则外，还会识别 Mark 打上的标识
+// 以及经过编码解码后的错误(按类型与消息比较)，见 IsMarked
+func Is(err, target error) bool {
+	if errors.Is(err, target) {
+		return true
+	}
+	if err == nil || target == nil {
+		return false
+	}
+	// 错误链中没有标记、解码得到的错误时 不必按标识比较
+	if _, decoded := target.(originalTyper); !decoded && !hasIdentityNode(err) {
+		return false
+	}
+	return IsMarked(err, target)
+}
+
+// hasIdentityNode 错误链(不包括次要错误)中是否有 Mark 标记或解码得到的错误
+// 不分配内存 用于 Is 的快速路径
+func hasIdentityNode(err error) bool {
+	for err != nil {
+		switch err.(type) {
+		case *withMark, originalTyper:
+			return true
+		}
+		for _, e := range UnwrapMulti(err) {
+			if hasIdentityNode(e) {
+				return true
+			}
+		}
+		err = UnwrapOnce(err)
+	}
+	return false
+}
+
+var _ error = (*withMark)(nil)
+var _ ErrorPrinter = (*withMark)(nil)
+var _ fmt.Formatter = (*withMark)(nil)
+
+type withMark struct {
+	cause error
+	mark  markIdentity
+}
+
+func (e *withMark) Error() string                 { return e.cause.Error() }
+func (e *withMark) Cause() error                  { return e.cause }
+func (e *withMark) Unwrap() error                 { return e.cause }
+func (e *withMark) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// PrintError implements ErrorPrinter.
+func (e *withMark) PrintError(p Printer) (next error) {
+	p.PrintDetailf("marked as: %v: %v", Safe(e.mark.Type), e.mark.Message)
+	return e.cause
+}
+
+func init() {
+	markKey := GetTypeKey(&withMark{})
+	RegisterWrapperEncoder(markKey, func(err error) (string, json.RawMessage) {
+		b, _ := json.Marshal(err.(*withMark).mark)
+		return "", b
+	})
+	RegisterWrapperDecoder(markKey, func(cause error, _ string, payload json.RawMessage) error {
+		e := &withMark{cause: cause}
+		json.Unmarshal(payload, &e.mark)
+		return e
+	})
+}
@@ -0,0 +1,73 @@
+package errors_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+var errConflict = errors.New("conflict")
+
+func TestMark(t *testing.T) {
+	err := errors.Mark(errors.Errorf("row %d updated concurrently", 3), errConflict)
+	if err.Error() != "row 3 updated concurrently" {
+		t.Errorf("Mark should not change message, got %q", err.Error())
+	}
+	if stderrors.Is(err, errConflict) {
+		t.Errorf("stdlib Is does not know marks")
+	}
+	wrapped := errors.Wrap(err, "save")
+	if !errors.Is(wrapped, errConflict) || !errors.IsMarked(wrapped, errConflict) {
+		t.Errorf("Is should honor marks")
+	}
+	if errors.Is(wrapped, io.EOF) {
+		t.Errorf("unexpected match")
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", wrapped), "marked as: *code.gopub.tech/errors.fundamental: conflict") {
+		t.Errorf("%%+v should show the mark: %+v", wrapped)
+	}
+	if errors.Mark(nil, errConflict) != nil || errors.Mark(io.EOF, nil) != io.EOF {
+		t.Errorf("Mark with nil")
+	}
+
+	// 次要错误不参与匹配
+	err = errors.WithSecondary(io.EOF, errors.Mark(io.EOF, errConflict))
+	if errors.Is(err, errConflict) {
+		t.Errorf("marks in secondary errors should be ignored")
+	}
+}
+
+func TestMarkDecoded(t *testing.T) {
+	decoded := roundTrip(t, errors.Wrap(errors.Mark(context.DeadlineExceeded, errConflict), "read"))
+	if !errors.Is(decoded, errConflict) {
+		t.Errorf("marks should survive encoding")
+	}
+	if !errors.Is(decoded, context.DeadlineExceeded) || errors.Is(decoded, context.Canceled) {
+		t.Errorf("decoded errors should match by type and message")
+	}
+
+	// 本地错误与解码得到的 reference 比较
+	ref := roundTrip(t, errConflict)
+	if !errors.Is(errors.Wrap(errConflict, "x"), ref) || errors.Is(errors.New("other"), ref) {
+		t.Errorf("local errors should match decoded reference by identity")
+	}
+	// 消息相同但类型不同
+	if errors.Is(roundTrip(t, stderrors.New("conflict")), errConflict) {
+		t.Errorf("type should be part of the identity")
+	}
+}
+
+func BenchmarkIsMiss(b *testing.B) {
+	err := errors.Wrap(errors.WithMessage(errors.New("leaf"), "msg"), "wrap")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if errors.Is(err, io.EOF) {
+			b.Fatal("unexpected match")
+		}
+	}
+}
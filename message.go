@@ -9,6 +9,9 @@ var _ fmt.Formatter = (*withPrefix)(nil)
 type withPrefix struct {
 	error
 	string
+	redactable redactable
+	// format Errorf, WithMessagef 的格式字符串
+	format string
 }
 
 func (e *withPrefix) Error() string {
@@ -25,7 +28,7 @@ func (e *withPrefix) Format(f fmt.State, verb rune) {
 
 // PrintError implements Formatter.
 func (e *withPrefix) PrintError(p Printer) (next error) {
-	p.Print(e.string)
+	p.Print(message{plain: e.string, redactable: e.redactable})
 	return e.error
 }
 
@@ -34,8 +37,11 @@ var _ ErrorPrinter = (*withNewMessage)(nil)
 var _ fmt.Formatter = (*withNewMessage)(nil)
 
 type withNewMessage struct {
-	message string
-	cause   error
+	message    string
+	cause      error
+	redactable redactable
+	// format Errorf 的格式字符串
+	format string
 }
 
 func (e *withNewMessage) Error() string { return e.message }
@@ -49,6 +55,6 @@ func (e *withNewMessage) Format(f fmt.State, verb rune) {
 
 // PrintError implements Formatter.
 func (e *withNewMessage) PrintError(p Printer) (next error) {
-	p.Print(e.message)
+	p.Print(message{plain: e.message, redactable: e.redactable})
 	return nil
 }
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// originalTyper 解码得到的错误 记录了编码前的原始类型
+type originalTyper interface{ originalType() string }
+
+// stackFramesProvider 已符号化的堆栈(如解码得到的错误)
+type stackFramesProvider = interface{ StackFrames() []StackFrame }
+
+var _ error = (*opaqueLeaf)(nil)
+var _ ErrorPrinter = (*opaqueLeaf)(nil)
+var _ fmt.Formatter = (*opaqueLeaf)(nil)
+
+// opaqueLeaf 解码时未注册解码函数的叶子错误
+type opaqueLeaf struct {
+	msg        string
+	redactable redactable
+	typeKey    string
+	frames     []StackFrame
+	truncated  int
+	payload    json.RawMessage
+}
+
+func (e *opaqueLeaf) Error() string                 { return e.msg }
+func (e *opaqueLeaf) StackFrames() []StackFrame     { return e.frames }
+func (e *opaqueLeaf) framesTruncated() int          { return e.truncated }
+func (e *opaqueLeaf) originalType() string          { return e.typeKey }
+func (e *opaqueLeaf) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+func (e *opaqueLeaf) PrintError(p Printer) (next error) {
+	p.Print(message{plain: e.msg, redactable: e.redactable})
+	return nil
+}
+
+var _ error = (*opaqueWrapper)(nil)
+var _ ErrorPrinter = (*opaqueWrapper)(nil)
+var _ fmt.Formatter = (*opaqueWrapper)(nil)
+
+// opaqueWrapper 解码时未注册解码函数的包装错误
+type opaqueWrapper struct {
+	cause       error
+	prefix      string
+	redactable  redactable
+	fullMessage bool
+	typeKey     string
+	frames      []StackFrame
+	truncated   int
+	payload     json.RawMessage
+}
+
+func (e *opaqueWrapper) Error() string {
+	switch {
+	case e.fullMessage:
+		return e.prefix
+	case e.prefix == "":
+		return e.cause.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.prefix, e.cause)
+}
+
+func (e *opaqueWrapper) Cause() error                  { return e.cause }
+func (e *opaqueWrapper) Unwrap() error                 { return e.cause }
+func (e *opaqueWrapper) StackFrames() []StackFrame     { return e.frames }
+func (e *opaqueWrapper) framesTruncated() int          { return e.truncated }
+func (e *opaqueWrapper) originalType() string          { return e.typeKey }
+func (e *opaqueWrapper) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+func (e *opaqueWrapper) PrintError(p Printer) (next error) {
+	if e.prefix != "" {
+		p.Print(message{plain: e.prefix, redactable: e.redactable})
+	}
+	if e.fullMessage {
+		return nil
+	}
+	return e.cause
+}
+
+var _ error = (*opaqueMulti)(nil)
+var _ ErrorPrinter = (*opaqueMulti)(nil)
+var _ fmt.Formatter = (*opaqueMulti)(nil)
+
+// opaqueMulti 解码时未注册解码函数的、包装了多个错误的错误
+type opaqueMulti struct {
+	msg       string
+	typeKey   string
+	causes    []error
+	frames    []StackFrame
+	truncated int
+	payload   json.RawMessage
+}
+
+func (e *opaqueMulti) Error() string                 { return e.msg }
+func (e *opaqueMulti) Unwrap() []error               { return e.causes }
+func (e *opaqueMulti) StackFrames() []StackFrame     { return e.frames }
+func (e *opaqueMulti) framesTruncated() int          { return e.truncated }
+func (e *opaqueMulti) originalType() string          { return e.typeKey }
+func (e *opaqueMulti) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+func (e *opaqueMulti) PrintError(p Printer) (next error) {
+	p.Print(e.msg)
+	return nil
+}
@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Recover 在 defer 中使用，将 panic 转换为错误赋值给 *errp:
+//
+//	func do() (err error) {
+//		defer errors.Recover(&err)
+//		// ...
+//	}
+//
+// 如果 panic 前 *errp 已有错误，该错误会作为次要错误保留
+func Recover(errp *error) {
+	if r := recover(); r != nil {
+		err := fromPanic(r, callers())
+		if *errp != nil {
+			err = WithSecondary(err, *errp)
+		}
+		*errp = err
+	}
+}
+
+// FromPanic 将 recover() 得到的值转换为错误。
+// 值为 error 时作为 cause 保留(Is/As 仍然可用)；
+// 在 defer 中 recover 后调用时，记录的堆栈从 panic 发生处开始。
+// v 为 nil 时返回 nil
+func FromPanic(v any) error {
+	if v == nil {
+		return nil
+	}
+	return fromPanic(v, callers())
+}
+
+func fromPanic(v any, st *stack) error {
+	st.pcs = trimPanicFrames(st.pcs)
+	e := &panicError{value: v, stack: st}
+	if err, ok := v.(error); ok {
+		e.cause = err
+	}
+	return e
+}
+
+// trimPanicFrames 去掉 defer 函数及 runtime 中处理 panic 的帧
+// 使堆栈从 panic 发生处开始
+func trimPanicFrames(pcs []uintptr) []uintptr {
+	for i, pc := range pcs {
+		if Frame(pc).Function() != "runtime.gopanic" {
+			continue
+		}
+		for i++; i < len(pcs); i++ {
+			// runtime.panicmem, runtime.sigpanic, runtime.goPanicIndex ...
+			if fn := Frame(pcs[i]).Function(); !strings.HasPrefix(fn, "runtime.") {
+				break
+			}
+		}
+		return pcs[i:]
+	}
+	return pcs
+}
+
+// IsPanic 错误树中是否有由 panic 转换而来的错误
+func IsPanic(err error) bool {
+	_, ok := PanicValue(err)
+	return ok
+}
+
+// PanicValue 获取错误树中由 panic 转换而来的错误的原始值
+func PanicValue(err error) (v any, ok bool) {
+	Walk(err, func(node Node) WalkAction {
+		if e, is := node.Err.(*panicError); is {
+			v, ok = e.value, true
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return
+}
+
+var _ error = (*panicError)(nil)
+var _ ErrorPrinter = (*panicError)(nil)
+var _ fmt.Formatter = (*panicError)(nil)
+
+type panicError struct {
+	value any
+	// value 为 error 时的原始错误
+	cause error
+	*stack
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+func (e *panicError) Cause() error  { return e.cause }
+func (e *panicError) Unwrap() error { return e.cause }
+
+func (e *panicError) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// PrintError implements ErrorPrinter.
+func (e *panicError) PrintError(p Printer) (next error) {
+	p.PrintDetailf("\npanic value type: %v", Safe(fmt.Sprintf("%T", e.value)))
+	if _, ok := e.value.(runtime.Error); ok {
+		p.PrintDetailf(" (runtime error)")
+	}
+	if e.cause != nil {
+		p.Printf("panic")
+		return e.cause
+	}
+	p.Printf("panic: %v", e.value)
+	return nil
+}
@@ -0,0 +1,83 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func panicWith(v any) (err error) {
+	defer errors.Recover(&err)
+	panic(v)
+}
+
+func panicIndex(i int) (err error) {
+	defer errors.Recover(&err)
+	var s []int
+	return fmt.Errorf("%d", s[i])
+}
+
+func TestRecover(t *testing.T) {
+	err := panicWith("boom")
+	if err.Error() != "panic: boom" {
+		t.Errorf("Error() got %q", err.Error())
+	}
+	if v, ok := errors.PanicValue(errors.Wrap(err, "wrap")); !ok || v != "boom" {
+		t.Errorf("PanicValue got %v, %v", v, ok)
+	}
+	r := errors.NewReport(err)
+	if !strings.HasSuffix(r.Stack[0].Function, "errors_test.panicWith") {
+		t.Errorf("stack should start at the panic site, got %s", r.Stack[0].Function)
+	}
+
+	err = panicWith(io.EOF)
+	if !errors.Is(err, io.EOF) || err.Error() != "panic: EOF" {
+		t.Errorf("error panic value should be the cause, got %v", err)
+	}
+
+	err = panicIndex(1)
+	t.Logf("%+v", err)
+	var re runtime.Error
+	if !errors.As(err, &re) || !strings.Contains(errors.Detail(err), "(runtime error)") {
+		t.Errorf("runtime error should be classified")
+	}
+	if r := errors.NewReport(err); !strings.HasSuffix(r.Stack[0].Function, "errors_test.panicIndex") {
+		t.Errorf("stack should start at the panic site, got %s", r.Stack[0].Function)
+	}
+	if errors.IsPanic(errFmt) || !errors.IsPanic(err) {
+		t.Errorf("IsPanic failed")
+	}
+}
+
+func TestRecoverKeepsError(t *testing.T) {
+	f := func() (err error) {
+		defer errors.Recover(&err)
+		err = errors.New("before panic")
+		panic("boom")
+	}
+	err := f()
+	if !strings.Contains(errors.Detail(err), "before panic") {
+		t.Errorf("previous error should be kept as secondary")
+	}
+}
+
+func TestFromPanic(t *testing.T) {
+	if errors.FromPanic(nil) != nil {
+		t.Errorf("FromPanic(nil) want nil")
+	}
+	var err error
+	func() {
+		defer func() { err = errors.FromPanic(recover()) }()
+		panic(fmt.Sprintf("user %s", "secret"))
+	}()
+	if errors.Redact(err) != "panic: ‹×›" {
+		t.Errorf("panic value should be redacted, got %s", errors.Redact(err))
+	}
+	if r := errors.NewReport(err); !strings.Contains(r.Stack[0].Function, "TestFromPanic") {
+		t.Errorf("stack should start at the panic site, got %s", r.Stack[0].Function)
+	}
+}
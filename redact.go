@@ -0,0 +1,145 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"code.gopub.tech/errors/fmtfwd"
+)
+
+// 脱敏标记: 不安全的内容使用 ‹› 包围，脱敏后输出为 ‹×›
+const (
+	redactStart    = "‹"
+	redactEnd      = "›"
+	redactedMarker = redactStart + "×" + redactEnd
+)
+
+var markerEscaper = strings.NewReplacer(redactStart, "?", redactEnd, "?")
+
+// redactable 带有脱敏标记的字符串，不安全的部分使用 ‹› 包围
+type redactable string
+
+// safeRedactable 整条消息都是安全的(如字面量)
+func safeRedactable(s string) redactable {
+	return redactable(markerEscaper.Replace(s))
+}
+
+// unsafeRedactable 整条消息都是不安全的
+func unsafeRedactable(s string) redactable {
+	if s == "" {
+		return ""
+	}
+	return redactable(redactStart + markerEscaper.Replace(s) + redactEnd)
+}
+
+// redact 将不安全的部分替换为 ‹×›
+func (r redactable) redact() string {
+	s := string(r)
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, redactStart)
+		if i < 0 {
+			sb.WriteString(s)
+			break
+		}
+		sb.WriteString(s[:i])
+		sb.WriteString(redactedMarker)
+		s = s[i+len(redactStart):]
+		if j := strings.Index(s, redactEnd); j >= 0 {
+			s = s[j+len(redactEnd):]
+		} else {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// redactableSprintf 按格式生成带脱敏标记的字符串:
+// 格式字符串与 Safe 标记的参数视为安全，其他参数视为不安全
+func redactableSprintf(format string, args ...any) redactable {
+	wrapped := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case SafeValue:
+			wrapped[i] = escapedArg{v.value}
+		default:
+			wrapped[i] = unsafeArg{arg}
+		}
+	}
+	return redactable(fmt.Sprintf(markerEscaper.Replace(format), wrapped...))
+}
+
+// redactablePrefix 从 `prefix: ‹cause›` 形式的脱敏消息中提取前缀，
+// 提取失败时整个前缀视为不安全
+func redactablePrefix(full redactable, causeMsg, prefix string) redactable {
+	cause := string(unsafeRedactable(causeMsg))
+	if s := string(full); strings.HasSuffix(s, cause) {
+		s = strings.TrimSuffix(s[:len(s)-len(cause)], " ")
+		return redactable(strings.TrimSuffix(s, ":"))
+	}
+	return unsafeRedactable(prefix)
+}
+
+// SafeValue 被标记为安全的值，脱敏时原样输出
+type SafeValue struct{ value any }
+
+// Safe 将值标记为安全，在 Errorf, Wrapf, WithMessagef 等函数中作为参数时，
+// 该值在脱敏输出(Redact, Redacted)时会原样保留
+func Safe(v any) SafeValue { return SafeValue{value: v} }
+
+// Format implements fmt.Formatter.
+func (s SafeValue) Format(f fmt.State, verb rune) {
+	fmtfwd.ReproducePrintf(f, f, verb, s.value)
+}
+
+// escapedArg 安全的参数 转义其中的脱敏标记
+type escapedArg struct{ value any }
+
+func (a escapedArg) Format(f fmt.State, verb rune) {
+	var buf bytes.Buffer
+	fmtfwd.ReproducePrintf(&buf, f, verb, a.value)
+	f.Write([]byte(markerEscaper.Replace(buf.String())))
+}
+
+// unsafeArg 不安全的参数 使用脱敏标记包围
+type unsafeArg struct{ value any }
+
+func (a unsafeArg) Format(f fmt.State, verb rune) {
+	var buf bytes.Buffer
+	fmtfwd.ReproducePrintf(&buf, f, verb, a.value)
+	f.Write([]byte(unsafeRedactable(buf.String())))
+}
+
+// message 错误消息 正常输出原文；脱敏输出时仅保留安全的部分
+type message struct {
+	plain      string
+	redactable redactable
+}
+
+// Format implements fmt.Formatter.
+func (m message) Format(f fmt.State, verb rune) {
+	fmtfwd.ReproducePrintf(f, f, verb, m.plain)
+}
+
+// Redact 获取脱敏后的错误信息，不安全的内容被替换为 ‹×›
+func Redact(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return fmt.Sprint(Redacted(err))
+}
+
+// Redacted 将错误包装为脱敏输出的 fmt.Formatter，
+// 使用 %v 或 %+v 格式化时，整棵错误树(包括次要错误、聚合的多个错误)中
+// 不安全的内容都会被替换为 ‹×›。
+//
+// 本包创建的错误中，New, WithMessage, Wrap 的消息视为安全，
+// Errorf, Wrapf, WithMessagef 的格式字符串及 Safe 标记的参数视为安全，
+// 其他参数及外部错误类型的消息均视为不安全。
+func Redacted(err error) FormattableError {
+	if err == nil {
+		return nil
+	}
+	return &errorFormatter{error: err, redact: true}
+}
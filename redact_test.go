@@ -0,0 +1,66 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestRedact(t *testing.T) {
+	email := "alice@example.com"
+	leaf := errors.Errorf("user %s not found, id=%d", email, errors.Safe(42))
+	if leaf.Error() != "user alice@example.com not found, id=42" {
+		t.Errorf("Error() should not be affected, got %q", leaf.Error())
+	}
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{nil, "<nil>"},
+		{errors.New("literal"), "literal"},
+		{leaf, "user ‹×› not found, id=42"},
+		{errors.Wrapf(leaf, "token %q", "secret"), "token ‹×›: user ‹×› not found, id=42"},
+		{errors.WithMessage(leaf, "lookup"), "lookup: user ‹×› not found, id=42"},
+		{errors.Errorf("load %s: %w", "secret", leaf), "load ‹×›: user ‹×› not found, id=42"},
+		{errors.Errorf("%w (%s)", leaf, errors.Safe("retry")), "‹×› (retry)"},
+		{fmt.Errorf("foreign %s: %w", email, leaf), "‹×›: user ‹×› not found, id=42"},
+		{errors.Errorf("open %s failed: %v", "secret", io.EOF), "open ‹×› failed: ‹×›"},
+		{errFmt, "‹×›"},
+		{errors.Join(leaf, errors.New("other")), "user ‹×› not found, id=42\nother"},
+		{errors.Errorf("arg %s marker ‹%s›", "‹x›", errors.Safe("‹safe›")), "arg ‹×› marker ??safe??"},
+	} {
+		if got := errors.Redact(tt.err); got != tt.want {
+			t.Errorf("Redact(%v)\nwant %q\ngot  %q", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestRedactedDetail(t *testing.T) {
+	secret := "alice@example.com"
+	err := errors.Wrap(
+		errors.WithSecondary(
+			errors.Join(errors.Errorf("user %s", secret), fmt.Errorf("raw %s", secret)),
+			errors.Errorf("cleanup %s failed", secret),
+		),
+		"handle",
+	)
+	detail := fmt.Sprintf("%+v", errors.Redacted(err))
+	t.Logf("%s", detail)
+	if strings.Contains(detail, secret) {
+		t.Errorf("secret leaked")
+	}
+	for _, want := range []string{"handle: user ‹×›", "cleanup ‹×› failed", "-- stack trace:", "Error types:"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("redacted detail should contain %q", want)
+		}
+	}
+	if !strings.Contains(errors.Detail(err), secret) {
+		t.Errorf("normal %%+v should not redact")
+	}
+	if s := fmt.Sprintf("%#v", errors.Redacted(err)); strings.Contains(s, secret) {
+		t.Errorf("%%#v should be redacted")
+	}
+}
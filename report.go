@@ -0,0 +1,76 @@
+package errors
+
+import "encoding/json"
+
+// Report 错误树的结构化表示，与 %+v 输出的树形结构一一对应。
+// 可以直接使用 encoding/json 序列化，便于日志系统按字段检索。
+type Report struct {
+	// Error 完整的错误信息，仅根节点有值
+	Error string `json:"error,omitempty"`
+	// Message 本节点的简单消息
+	Message string `json:"message,omitempty"`
+	// Detail 本节点的详细信息（仅 %+v 时可见的内容）
+	Detail string `json:"detail,omitempty"`
+	// Type 本节点的 Go 类型
+	Type string `json:"type"`
+	// Stack 本节点附加的堆栈
+	Stack []StackFrame `json:"stack,omitempty"`
+	// StackElided 堆栈与其他节点重复的部分已被省略
+	StackElided bool `json:"stackElided,omitempty"`
+	// StackHidden 被过滤器隐藏的帧数 见 SetFrameFilter
+	StackHidden int `json:"stackHidden,omitempty"`
+	// StackTruncated 超出最大深度而未被记录的帧数
+	StackTruncated int `json:"stackTruncated,omitempty"`
+	// Secondary 附加的次要错误
+	Secondary *Report `json:"secondary,omitempty"`
+	// Children 被包装的错误
+	Children []*Report `json:"children,omitempty"`
+}
+
+// NewReport 解析错误树，得到结构化的 Report
+func NewReport(err error) *Report {
+	if err == nil {
+		return nil
+	}
+	p := state{}
+	p.entry = p.buildTree(err, true)
+	p.printErrorString()
+	r := newReportNode(p.entry)
+	r.Error = p.finalBuf.String()
+	return r
+}
+
+// JSON 将错误树序列化为 JSON
+func JSON(err error) ([]byte, error) {
+	return json.Marshal(NewReport(err))
+}
+
+// newReportNode 递归地将 formatEntry 转换为 Report
+func newReportNode(entry *formatEntry) *Report {
+	r := &Report{
+		Message:        string(entry.simple),
+		Detail:         string(entry.detail),
+		Type:           errorTypeName(entry.err),
+		StackElided:    entry.elidedStackTrace,
+		StackTruncated: entry.truncatedFrames,
+	}
+	if e, ok := entry.err.(*withSecondaryError); ok {
+		// 次要错误单独结构化输出 不再以文本形式重复
+		r.Detail = secondaryErrorTitle
+		r.Secondary = NewReport(e.secondaryError)
+	}
+	var frames []StackFrame
+	if len(entry.stackTrace) > 0 {
+		frames = symbolize(entry.stackTrace)
+	} else if len(entry.stackFrames) > 0 {
+		frames = entry.stackFrames
+	}
+	if len(frames) > 0 {
+		frames, r.StackHidden = filterFrames(frames)
+		r.Stack = rewriteFrames(frames)
+	}
+	for _, child := range entry.wraps {
+		r.Children = append(r.Children, newReportNode(child))
+	}
+	return r
+}
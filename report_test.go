@@ -0,0 +1,47 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestNewReport(t *testing.T) {
+	if r := errors.NewReport(nil); r != nil {
+		t.Errorf("NewReport(nil) want nil, got: %+v", r)
+	}
+	err := errors.Wrap(errors.Join(errFmt, errLeafNew), "prefix")
+	r := errors.NewReport(err)
+	if r.Error != err.Error() {
+		t.Errorf("Error want %q, got %q", err.Error(), r.Error)
+	}
+	if r.Type != "*errors.withStack" || len(r.Stack) == 0 {
+		t.Errorf("root want *errors.withStack with stack, got %s", r.Type)
+	}
+	if len(r.Children) != 1 || r.Children[0].Message != "prefix" {
+		t.Errorf("unexpected children: %+v", r.Children)
+	}
+	join := r.Children[0].Children[0].Children[0]
+	if join.Type != "*errors.joinError" || len(join.Children) != 2 {
+		t.Errorf("unexpected join node: %+v", join)
+	}
+	if leaf := join.Children[1]; leaf.Stack[0].Function == "" || leaf.Stack[0].Line == 0 {
+		t.Errorf("leaf stack not symbolized: %+v", leaf.Stack[0])
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b, err := errors.JSON(errors.WithSecondary(errors.New("main"), errFmt))
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	t.Logf("%s", b)
+	var r errors.Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if r.Error != "main" || r.Secondary == nil || r.Secondary.Message != errFmt.Error() {
+		t.Errorf("unexpected report: %+v", r)
+	}
+}
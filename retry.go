@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MarkRetryable 将错误标记为可重试的(暂时性的)失败，不改变错误消息
+func MarkRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &withRetry{cause: err}
+}
+
+// WithRetryAfter 将错误标记为可重试的，并建议至少等待 d 后再重试。
+// Retry 会按该时间等待，可通过 RetryAfter 获取
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &withRetry{cause: err, after: d}
+}
+
+// IsRetryable 错误是否值得重试。错误树(不包括次要错误)中有以下错误时返回 true:
+//   - MarkRetryable, WithRetryAfter 标记过的错误
+//   - `Temporary() bool` 或 `Timeout() bool` 返回 true 的错误，如 net.Error
+//   - context.DeadlineExceeded (单次尝试超时)
+//
+// context.Canceled 表示调用方已放弃，视为不可重试
+func IsRetryable(err error) bool {
+	var retryable bool
+	Walk(err, func(node Node) WalkAction {
+		if node.Edge == EdgeSecondary {
+			return WalkSkip
+		}
+		if _, ok := node.Err.(*withRetry); ok {
+			retryable = true
+		} else if e, ok := node.Err.(interface{ Temporary() bool }); ok && e.Temporary() {
+			retryable = true
+		} else if e, ok := node.Err.(interface{ Timeout() bool }); ok && e.Timeout() {
+			retryable = true // 包括 context.DeadlineExceeded
+		}
+		if retryable {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return retryable
+}
+
+// RetryAfter 获取错误树(不包括次要错误)中最外层由 WithRetryAfter 建议的等待时间
+func RetryAfter(err error) (d time.Duration, ok bool) {
+	Walk(err, func(node Node) WalkAction {
+		if node.Edge == EdgeSecondary {
+			return WalkSkip
+		}
+		if e, is := node.Err.(*withRetry); is && e.after > 0 {
+			d, ok = e.after, true
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return
+}
+
+// RetryPolicy 重试策略，零值表示最多尝试 3 次，首次重试前等待 100ms，之后每次等待时间翻倍
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试的次数(包括第一次)，<= 0 时为 3
+	MaxAttempts int
+	// Delay 首次重试前的等待时间，<= 0 时为 100ms
+	Delay time.Duration
+	// MaxDelay 最长等待时间，<= 0 表示不限制
+	MaxDelay time.Duration
+	// Multiplier 每次重试后等待时间的倍数，< 1 时为 2
+	Multiplier float64
+}
+
+const (
+	defaultRetryAttempts = 3
+	defaultRetryDelay    = 100 * time.Millisecond
+)
+
+// delay 第 attempt 次(从 1 开始)失败后的等待时间
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	if d, ok := RetryAfter(err); ok {
+		return d
+	}
+	d, multiplier := p.Delay, p.Multiplier
+	if d <= 0 {
+		d = defaultRetryDelay
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d = time.Duration(float64(d) * multiplier)
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// Retry 按策略重复执行 fn 直到成功、遇到不可重试的错误(见 IsRetryable)、
+// 达到最大尝试次数或 ctx 结束。
+// 成功时返回 nil，否则返回所有尝试的错误的聚合，
+// 每次尝试的错误以 `attempt N` 为前缀；因 ctx 结束而停止时 ctx.Err() 也会被聚合
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryAttempts
+	}
+	var errs []error
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		label := fmt.Sprintf("attempt %d", attempt)
+		errs = append(errs, &withPrefix{
+			error:      err,
+			string:     label,
+			redactable: safeRedactable(label),
+		})
+		if attempt >= maxAttempts || !IsRetryable(err) {
+			break
+		}
+		timer := time.NewTimer(policy.delay(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			errs = append(errs, ctx.Err())
+		case <-timer.C:
+			continue
+		}
+		break
+	}
+	return &withStack{
+		error: join(errs...),
+		stack: callers(),
+	}
+}
+
+var _ error = (*withRetry)(nil)
+var _ ErrorPrinter = (*withRetry)(nil)
+var _ fmt.Formatter = (*withRetry)(nil)
+
+type withRetry struct {
+	cause error
+	// 建议的等待时间 0 表示未指定
+	after time.Duration
+}
+
+func (e *withRetry) Error() string                 { return e.cause.Error() }
+func (e *withRetry) Cause() error                  { return e.cause }
+func (e *withRetry) Unwrap() error                 { return e.cause }
+func (e *withRetry) Format(s fmt.State, verb rune) { FormatError(e, s, verb) }
+
+// PrintError implements ErrorPrinter.
+func (e *withRetry) PrintError(p Printer) (next error) {
+	if e.after > 0 {
+		p.PrintDetailf("retryable, retry after %v", Safe(e.after))
+	} else {
+		p.PrintDetailf("retryable")
+	}
+	return e.cause
+}
+
+func init() {
+	retryKey := GetTypeKey(&withRetry{})
+	RegisterWrapperEncoder(retryKey, func(err error) (string, json.RawMessage) {
+		b, _ := json.Marshal(err.(*withRetry).after)
+		return "", b
+	})
+	RegisterWrapperDecoder(retryKey, func(cause error, _ string, payload json.RawMessage) error {
+		e := &withRetry{cause: cause}
+		json.Unmarshal(payload, &e.after)
+		return e
+	})
+}
@@ -0,0 +1,99 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gopub.tech/errors"
+)
+
+type timeoutErr struct{ timeout bool }
+
+func (e *timeoutErr) Error() string   { return "i/o timeout" }
+func (e *timeoutErr) Timeout() bool   { return e.timeout }
+func (e *timeoutErr) Temporary() bool { return false }
+
+var _ net.Error = (*timeoutErr)(nil)
+
+func TestIsRetryable(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{io.EOF, false},
+		{errors.Wrap(errors.MarkRetryable(io.EOF), "read"), true},
+		{errors.WithRetryAfter(io.EOF, time.Second), true},
+		{errors.Wrap(&timeoutErr{timeout: true}, "dial"), true},
+		{&timeoutErr{}, false},
+		{errors.WithStack(context.DeadlineExceeded), true},
+		{errors.WithStack(context.Canceled), false},
+		{errors.WithSecondary(io.EOF, errors.MarkRetryable(io.EOF)), false},
+		{roundTrip(t, errors.MarkRetryable(io.EOF)), true},
+	} {
+		if got := errors.IsRetryable(c.err); got != c.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+	err := errors.WithRetryAfter(io.EOF, time.Second)
+	if d, ok := errors.RetryAfter(errors.Wrap(err, "x")); !ok || d != time.Second {
+		t.Errorf("RetryAfter got %v %v", d, ok)
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "retryable, retry after 1s") {
+		t.Errorf("%%+v should show retry info")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := errors.RetryPolicy{MaxAttempts: 5, Delay: time.Millisecond}
+	var calls int
+	err := errors.Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.MarkRetryable(errors.Errorf("busy %d", calls))
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Retry should succeed after 3 attempts: %v, %d", err, calls)
+	}
+
+	calls = 0
+	err = errors.Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.MarkRetryable(errors.New("busy"))
+		}
+		return errors.New("bad request")
+	})
+	t.Logf("%+v", err)
+	if calls != 2 || err.Error() != "attempt 1: busy\nattempt 2: bad request" {
+		t.Errorf("Retry should stop on permanent errors: %d %q", calls, err.Error())
+	}
+
+	calls = 0
+	err = errors.Retry(context.Background(), errors.RetryPolicy{MaxAttempts: 2, Delay: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		return errors.MarkRetryable(io.EOF)
+	})
+	if calls != 2 || err.Error() != "attempt 1: EOF\nattempt 2: EOF" {
+		t.Errorf("Retry should stop after MaxAttempts: %d %q", calls, err.Error())
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	err := errors.Retry(ctx, errors.RetryPolicy{}, func(ctx context.Context) error {
+		cancel()
+		return errors.WithRetryAfter(io.EOF, time.Hour)
+	})
+	if time.Since(start) > time.Second || err.Error() != "attempt 1: EOF\ncontext canceled" {
+		t.Errorf("Retry should stop when context is done: %q", err.Error())
+	}
+}
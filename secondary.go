@@ -6,6 +6,8 @@ var _ error = (*withSecondaryError)(nil)
 var _ ErrorPrinter = (*withSecondaryError)(nil)
 var _ fmt.Formatter = (*withSecondaryError)(nil)
 
+const secondaryErrorTitle = "secondary error attachment"
+
 type withSecondaryError struct {
 	cause          error
 	secondaryError error
@@ -28,6 +30,6 @@ func (e *withSecondaryError) Format(s fmt.State, verb rune) {
 
 func (e *withSecondaryError) PrintError(p Printer) error {
 	// 详细输出时，才会输出次要错误
-	p.PrintDetailf("secondary error attachment\n%+v", e.secondaryError)
+	p.PrintDetailf(secondaryErrorTitle+"\n%+v", e.secondaryError)
 	return e.cause
 }
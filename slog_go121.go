@@ -0,0 +1,152 @@
+//go:build go1.21
+
+package errors
+
+import (
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+)
+
+var logStackTrace atomic.Bool
+
+// SetLogStackTrace 设置输出到 log/slog 时是否包含完整堆栈，默认仅包含错误发生处的帧
+func SetLogStackTrace(enabled bool) {
+	logStackTrace.Store(enabled)
+}
+
+// LogValue 将错误转换为 log/slog 的分组值:
+//
+//	msg    错误信息
+//	code   错误码(见 WithCode)
+//	kind   错误类别(见 KindOf)
+//	types  沿 Cause 链由外到内各个错误的类型
+//	origin 最内层带堆栈的错误的发生处 形如 `pkg.Func file.go:12`
+//	stack  完整堆栈(见 SetLogStackTrace)
+//	fields 整棵错误树中的键值对(见 WithFields)
+//
+// 本包的错误类型都实现了 slog.LogValuer，其他错误可以使用 ReplaceAttr 转换
+func LogValue(err error) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+	attrs := []slog.Attr{slog.String("msg", err.Error())}
+	if code, ok := GetCode(err); ok {
+		attrs = append(attrs, slog.String("code", code.String()))
+	}
+	if kind := KindOf(err); kind != KindOther {
+		attrs = append(attrs, slog.String("kind", kind.String()))
+	}
+
+	var (
+		types []string
+		stack []StackFrame
+	)
+	for e := err; e != nil; e = UnwrapOnce(e) {
+		types = append(types, errorTypeName(e))
+		if st, ok := GetStackTrace(e); ok {
+			stack = symbolize(st)
+		} else if fp, ok := e.(stackFramesProvider); ok {
+			stack = fp.StackFrames()
+		}
+	}
+	attrs = append(attrs, slog.Any("types", types))
+
+	stack, _ = filterFrames(stack)
+	stack = rewriteFrames(stack)
+	if len(stack) > 0 {
+		attrs = append(attrs, slog.String("origin", formatLogFrame(stack[0])))
+		if logStackTrace.Load() {
+			lines := make([]string, len(stack))
+			for i, f := range stack {
+				lines[i] = formatLogFrame(f)
+			}
+			attrs = append(attrs, slog.Any("stack", lines))
+		}
+	}
+
+	if fields := Fields(err); len(fields) > 0 {
+		group := make([]any, len(fields))
+		for i, f := range fields {
+			group[i] = slog.Any(f.Key, f.Value)
+		}
+		attrs = append(attrs, slog.Group("fields", group...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func formatLogFrame(f StackFrame) string {
+	return f.Function + " " + f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// ReplaceAttr 用于 slog.HandlerOptions.ReplaceAttr，
+// 将属性中的其他错误类型(未实现 slog.LogValuer 的)同样按 LogValue 展开
+//
+//	slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+//		ReplaceAttr: errors.ReplaceAttr,
+//	}))
+func ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindAny {
+		if err, ok := a.Value.Any().(error); ok && err != nil {
+			a.Value = LogValue(err)
+		}
+	}
+	return a
+}
+
+// LogValue implements slog.LogValuer.
+func (e *fundamental) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withStack) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withPrefix) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withNewMessage) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withSecondaryError) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *joinError) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withHint) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withDetail) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withMark) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withFields) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withCode) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withKind) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *withRetry) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *panicError) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *omittedErrors) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *opaqueLeaf) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *opaqueWrapper) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *opaqueMulti) LogValue() slog.Value { return LogValue(e) }
+
+// LogValue implements slog.LogValuer.
+func (e *errorFormatter) LogValue() slog.Value { return LogValue(e.error) }
@@ -0,0 +1,68 @@
+//go:build go1.21
+
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func logJSON(t *testing.T, opts *slog.HandlerOptions, err error) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, opts)).Error("failed", "err", err)
+	t.Log(buf.String())
+	var m map[string]any
+	if e := json.Unmarshal(buf.Bytes(), &m); e != nil {
+		t.Fatalf("Unmarshal failed: %v", e)
+	}
+	v, _ := m["err"].(map[string]any)
+	return v
+}
+
+func TestLogValue(t *testing.T) {
+	err := errors.Wrap(errors.WithFields(errors.New("boom"), "user", 42), "handle")
+	v := logJSON(t, nil, err)
+	if v["msg"] != "handle: boom" {
+		t.Errorf("msg got %v", v["msg"])
+	}
+	if fmt.Sprint(v["types"]) != "[*errors.withStack *errors.withPrefix *errors.withFields *errors.fundamental]" {
+		t.Errorf("types got %v", v["types"])
+	}
+	if origin, _ := v["origin"].(string); !strings.HasPrefix(origin, "code.gopub.tech/errors_test.TestLogValue ") {
+		t.Errorf("origin got %v", v["origin"])
+	}
+	if v["stack"] != nil {
+		t.Errorf("stack should be omitted by default")
+	}
+	if fmt.Sprint(v["fields"]) != "map[user:42]" {
+		t.Errorf("fields got %v", v["fields"])
+	}
+
+	errors.SetLogStackTrace(true)
+	defer errors.SetLogStackTrace(false)
+	v = logJSON(t, nil, errors.Join(err, errors.New("other")))
+	if stack, _ := v["stack"].([]any); len(stack) == 0 {
+		t.Errorf("stack should be included")
+	}
+}
+
+func TestReplaceAttr(t *testing.T) {
+	err := fmt.Errorf("foreign: %w", errors.WithFields(errors.New("boom"), "k", "v"))
+	if v := logJSON(t, nil, err); v != nil {
+		t.Errorf("foreign errors are logged as string without ReplaceAttr")
+	}
+	v := logJSON(t, &slog.HandlerOptions{ReplaceAttr: errors.ReplaceAttr}, err)
+	if v["msg"] != "foreign: boom" || fmt.Sprint(v["fields"]) != "map[k:v]" {
+		t.Errorf("ReplaceAttr should expand foreign errors: %v", v)
+	}
+	if fmt.Sprint(v["types"]) != "[*fmt.wrapError *errors.withFields *errors.fundamental]" {
+		t.Errorf("types got %v", v["types"])
+	}
+}
@@ -6,33 +6,87 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// defaultMaxStackDepth 默认最多记录的堆栈帧数
+const defaultMaxStackDepth = 32
+
+var maxStackDepth int32 = defaultMaxStackDepth
+
+// SetMaxStackDepth 设置创建错误时最多记录的堆栈帧数，
+// n <= 0 时恢复为默认值 32
+func SetMaxStackDepth(n int) {
+	if n <= 0 {
+		n = defaultMaxStackDepth
+	}
+	atomic.StoreInt32(&maxStackDepth, int32(n))
+}
+
+// MaxStackDepth 获取创建错误时最多记录的堆栈帧数
+func MaxStackDepth() int {
+	return int(atomic.LoadInt32(&maxStackDepth))
+}
+
 // callers 获取本函数调用者的调用者的堆栈信息
-// runtime.Callers <- callers <- errors.New <- user
+// runtime.Callers <- capture <- callers <- errors.New <- user
 func callers() *stack {
-	const numFrames = 32
-	var pcs [numFrames]uintptr
-	n := runtime.Callers(3, pcs[:])
-	var st stack = pcs[0:n]
-	return &st
+	return capture(4, MaxStackDepth())
+}
+
+// callersDepth 同 callers 但指定最多记录的堆栈帧数
+func callersDepth(depth int) *stack {
+	if depth <= 0 {
+		depth = MaxStackDepth()
+	}
+	return capture(4, depth)
+}
+
+// capture 记录堆栈 超出 depth 的部分只记录被截断的帧数
+func capture(skip, depth int) *stack {
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip, pcs)
+	st := &stack{pcs: pcs[:n]}
+	if n == depth { // 缓冲区满了 可能有帧被截断
+		size := depth * 2
+		for {
+			all := make([]uintptr, size)
+			if total := runtime.Callers(skip, all); total < size {
+				st.truncated = total - depth
+				break
+			}
+			size *= 2
+		}
+	}
+	return st
 }
 
 // stack 堆栈信息
-type stack []uintptr
-type stackTraceProvider = interface{ StackTrace() []uintptr }
+type stack struct {
+	pcs []uintptr
+	// 超出最大深度而未被记录的帧数
+	truncated int
+}
+type stackTraceProvider = interface{ StackTrace() StackTrace }
+
+// stackTruncation 堆栈超出最大深度时被截断的帧数
+type stackTruncation = interface{ framesTruncated() int }
 
 var _ stackTraceProvider = (*stack)(nil)
+var _ stackTruncation = (*stack)(nil)
 
 // StackTrace implements stackTraceProvider
-func (s *stack) StackTrace() []uintptr {
-	f := make([]uintptr, len(*s))
-	for i := 0; i < len(f); i++ {
-		f[i] = (*s)[i]
+func (s *stack) StackTrace() StackTrace {
+	f := make(StackTrace, len(s.pcs))
+	for i, pc := range s.pcs {
+		f[i] = Frame(pc)
 	}
 	return f
 }
 
+func (s *stack) framesTruncated() int { return s.truncated }
+
 var ptrType = reflect.TypeOf(uintptr(0))
 
 // GetStackTrace 获取错误上附加的堆栈
@@ -40,7 +94,7 @@ var ptrType = reflect.TypeOf(uintptr(0))
 func GetStackTrace(err error) (st []uintptr, ok bool) {
 	if se, ok := err.(stackTraceProvider); ok {
 		f := se.StackTrace() // 本项目的 stack 直接调用 不用反射
-		return f, true
+		return f.pcs(), true
 	}
 	rt := reflect.TypeOf(err)
 	rm, has := rt.MethodByName("StackTrace")
@@ -71,24 +125,82 @@ func GetStackTrace(err error) (st []uintptr, ok bool) {
 	return
 }
 
+// StackFrame 符号化后的单个堆栈帧
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// frameCache 程序计数器到符号化结果的缓存
+var frameCache sync.Map // map[uintptr]StackFrame
+
+// symbolize 将程序计数器解析为函数名、文件、行号
+// runtime.Callers 会为被内联的调用单独返回 pc，所以每个 pc 对应一帧
+func symbolize(st []uintptr) []StackFrame {
+	frames := make([]StackFrame, 0, len(st))
+	for _, pc := range st {
+		frames = append(frames, symbolizePC(pc))
+	}
+	return frames
+}
+
+// symbolizePC 解析单个程序计数器 结果会被缓存
+func symbolizePC(pc uintptr) StackFrame {
+	if v, ok := frameCache.Load(pc); ok {
+		return v.(StackFrame)
+	}
+	frame := StackFrame{Function: "unknown", File: "unknown"}
+	if f, _ := runtime.CallersFrames([]uintptr{pc}).Next(); f.Function != "" || f.File != "" {
+		frame = StackFrame{
+			Function: f.Function,
+			File:     f.File,
+			Line:     f.Line,
+		}
+	}
+	frameCache.Store(pc, frame)
+	return frame
+}
+
 // StackDetail 获取堆栈详情
 func StackDetail(st []uintptr) string {
-	var sb strings.Builder
-	for _, f := range st {
-		sb.WriteString("\n")
-		pc := f - 1
-		fn := runtime.FuncForPC(pc)
-		if fn != nil {
-			sb.WriteString(fn.Name())
-			sb.WriteString("\n\t")
-			file, line := fn.FileLine(pc)
-			sb.WriteString(file)
-			sb.WriteString(":")
-			sb.WriteString(strconv.Itoa(line))
-		} else {
-			sb.WriteString("unknown\n\tunknown:0")
+	return formatFrames(symbolize(st), 0)
+}
+
+// formatFrames 将堆栈帧格式化为多行文本
+// 连续被过滤器隐藏的帧合并注明帧数
+// truncated > 0 时在末尾注明被截断的帧数
+func formatFrames(frames []StackFrame, truncated int) string {
+	var (
+		sb     strings.Builder
+		style  = GetStackStyle()
+		filter = GetFrameFilter()
+		hidden int
+	)
+	flushHidden := func() {
+		if hidden > 0 {
+			fmt.Fprintf(&sb, "\n[...%d frames hidden...]", hidden)
+			hidden = 0
 		}
 	}
+	for _, f := range frames {
+		if filter != nil && !filter(f) {
+			hidden++
+			continue
+		}
+		flushHidden()
+		f = style.rewrite(f)
+		sb.WriteString("\n")
+		sb.WriteString(f.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(f.File)
+		sb.WriteString(":")
+		sb.WriteString(strconv.Itoa(f.Line))
+	}
+	flushHidden()
+	if truncated > 0 {
+		fmt.Fprintf(&sb, "\n[...%d frames truncated...]", truncated)
+	}
 	return sb.String()
 }
 
@@ -109,6 +221,6 @@ func (e *withStack) Format(s fmt.State, verb rune) {
 }
 
 func (e *withStack) PrintError(p Printer) (next error) {
-	p.PrintDetail("attached stack trace")
+	p.PrintDetailf("attached stack trace")
 	return e.error
 }
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stackDetailFuncForPC 改用 runtime.CallersFrames 之前的实现，仅用于基准对比
+func stackDetailFuncForPC(st []uintptr) string {
+	var sb strings.Builder
+	for _, f := range st {
+		sb.WriteString("\n")
+		pc := f - 1
+		fn := runtime.FuncForPC(pc)
+		if fn != nil {
+			sb.WriteString(fn.Name())
+			sb.WriteString("\n\t")
+			file, line := fn.FileLine(pc)
+			sb.WriteString(file)
+			sb.WriteString(":")
+			sb.WriteString(strconv.Itoa(line))
+		} else {
+			sb.WriteString("unknown\n\tunknown:0")
+		}
+	}
+	return sb.String()
+}
+
+//go:noinline
+func benchStack(n int) []uintptr {
+	if n > 0 {
+		return benchStack(n - 1)
+	}
+	return callers().pcs
+}
+
+func BenchmarkStackDetail(b *testing.B) {
+	st := benchStack(16)
+	b.Run("FuncForPC", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			stackDetailFuncForPC(st)
+		}
+	})
+	b.Run("CallersFrames/uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			frameCache = sync.Map{}
+			StackDetail(st)
+		}
+	})
+	b.Run("CallersFrames/cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			StackDetail(st)
+		}
+	})
+}
+
+func BenchmarkDetail(b *testing.B) {
+	err := Wrap(New("leaf"), "retry")
+	for i := 0; i < b.N; i++ {
+		Detail(err)
+	}
+}
+
+//go:noinline
+func newStack() []uintptr { return callers().pcs }
+
+// inlinedMiddle 通常会被内联到 inlinedOuter 中(-gcflags=-l 时不会)
+func inlinedMiddle() []uintptr { return newStack() }
+
+//go:noinline
+func inlinedOuter() []uintptr { return inlinedMiddle() }
+
+func TestSymbolizeInlined(t *testing.T) {
+	st := inlinedOuter()
+	// 被内联时 pc 所在函数的入口是外层函数的入口
+	if runtime.FuncForPC(st[0]-1).Entry() != reflect.ValueOf(inlinedOuter).Pointer() {
+		t.Skip("inlinedMiddle is not inlined")
+	}
+	frames := symbolize(st)
+	if len(frames) < 2 {
+		t.Fatalf("want at least 2 frames, got %v", frames)
+	}
+	if !strings.HasSuffix(frames[0].Function, ".inlinedMiddle") ||
+		!strings.HasSuffix(frames[1].Function, ".inlinedOuter") {
+		t.Errorf("unexpected frames: %v", frames[:2])
+	}
+}
+
+func TestSymbolizeCache(t *testing.T) {
+	pc := newStack()[0]
+	frameCache.Delete(pc)
+	f := symbolizePC(pc)
+	if v, ok := frameCache.Load(pc); !ok || v.(StackFrame) != f || !strings.HasSuffix(f.Function, ".TestSymbolizeCache") {
+		t.Errorf("symbolizePC should be cached, got %v", f)
+	}
+	if f := symbolizePC(0); f.Function != "unknown" || f.File != "unknown" {
+		t.Errorf("unknown pc: %v", f)
+	}
+}
@@ -0,0 +1,55 @@
+package errors_test
+
+import (
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func recurse(n int, f func() error) error {
+	if n == 0 {
+		return f()
+	}
+	return recurse(n-1, f)
+}
+
+func TestMaxStackDepth(t *testing.T) {
+	defer errors.SetMaxStackDepth(0)
+	errors.SetMaxStackDepth(4)
+	if d := errors.MaxStackDepth(); d != 4 {
+		t.Errorf("MaxStackDepth want 4, got %d", d)
+	}
+	err := recurse(10, func() error { return errors.New("deep") })
+	detail := errors.Detail(err)
+	t.Logf("%s", detail)
+	if !strings.Contains(detail, "frames truncated...]") {
+		t.Errorf("truncation marker missing")
+	}
+	if r := errors.NewReport(err); len(r.Stack) != 4 || r.StackTruncated < 10 {
+		t.Errorf("want 4 frames and >=10 truncated, got %d, %d", len(r.Stack), r.StackTruncated)
+	}
+
+	errors.SetMaxStackDepth(0)
+	if d := errors.MaxStackDepth(); d != 32 {
+		t.Errorf("MaxStackDepth want default 32, got %d", d)
+	}
+	err = recurse(10, func() error { return errors.New("deep") })
+	if strings.Contains(errors.Detail(err), "frames truncated...]") {
+		t.Errorf("unexpected truncation marker")
+	}
+}
+
+func TestWithStackDepth(t *testing.T) {
+	if errors.WithStackDepth(nil, 1) != nil {
+		t.Errorf("WithStackDepth(nil) want nil")
+	}
+	err := recurse(3, func() error { return errors.WithStackDepth(errFmt, 2) })
+	r := errors.NewReport(err)
+	if len(r.Stack) != 2 || r.StackTruncated == 0 {
+		t.Errorf("want 2 frames with truncation, got %d, %d", len(r.Stack), r.StackTruncated)
+	}
+	if !strings.HasSuffix(r.Stack[0].Function, "TestWithStackDepth.func1") {
+		t.Errorf("first frame should be the caller, got %s", r.Stack[0].Function)
+	}
+}
@@ -0,0 +1,223 @@
+package errors
+
+import (
+	"path"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// StackStyle 输出堆栈时对源文件路径、函数名的改写方式。
+// 零值表示不做任何改写。
+type StackStyle struct {
+	// TrimPaths 去掉源文件路径中 GOROOT、GOPATH、模块缓存、模块根目录等前缀，
+	// 如 `/sdk/go1.21.6/src/testing/testing.go` => `testing/testing.go`，
+	// `/go/src/code.gopub.tech/example/main.go` => `code.gopub.tech/example/main.go`
+	TrimPaths bool
+	// TrimPrefixes 额外需要去掉的路径前缀
+	TrimPrefixes []string
+	// ShortPackages 将函数名中的包路径缩短为包名，
+	// 如 `code.gopub.tech/errors.New` => `errors.New`
+	ShortPackages bool
+	// PrettyFuncs 美化闭包、方法值、泛型实例化等编译器生成的函数名，
+	// 如 `pkg.(*T).Method.func1.2` => `pkg.(*T).Method.func1.func2`
+	PrettyFuncs bool
+}
+
+var stackStyle atomic.Value // StackStyle
+
+// SetStackStyle 设置输出堆栈时的改写方式，
+// 对 %+v、StackDetail、Report、JSON 及 Frame 的格式化均生效
+func SetStackStyle(style StackStyle) {
+	stackStyle.Store(style)
+}
+
+// GetStackStyle 获取输出堆栈时的改写方式
+func GetStackStyle() StackStyle {
+	style, _ := stackStyle.Load().(StackStyle)
+	return style
+}
+
+// rewriteFrames 按当前的 StackStyle 改写堆栈帧
+func rewriteFrames(frames []StackFrame) []StackFrame {
+	style := GetStackStyle()
+	if !style.enabled() {
+		return frames
+	}
+	result := make([]StackFrame, len(frames))
+	for i, f := range frames {
+		result[i] = style.rewrite(f)
+	}
+	return result
+}
+
+func (style StackStyle) enabled() bool {
+	return style.TrimPaths || len(style.TrimPrefixes) > 0 ||
+		style.ShortPackages || style.PrettyFuncs
+}
+
+// rewrite 改写单个堆栈帧
+func (style StackStyle) rewrite(f StackFrame) StackFrame {
+	if !style.enabled() {
+		return f
+	}
+	if file, ok := trimPrefixes(f.File, style.TrimPrefixes); ok {
+		f.File = file
+	} else if style.TrimPaths {
+		f.File = trimPath(f.Function, f.File)
+	}
+	if style.PrettyFuncs {
+		f.Function = prettyFunc(f.Function)
+	}
+	if style.ShortPackages {
+		f.Function = f.Function[strings.LastIndex(f.Function, "/")+1:]
+	}
+	return f
+}
+
+func trimPrefixes(file string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(file, prefix) {
+			return strings.TrimPrefix(file[len(prefix):], "/"), true
+		}
+	}
+	return file, false
+}
+
+// trimPath 去掉源文件路径中与构建环境相关的前缀
+func trimPath(function, file string) string {
+	env := detectBuildEnv()
+	if env.goroot != "" && strings.HasPrefix(file, env.goroot) {
+		return file[len(env.goroot):]
+	}
+	// 模块缓存 `$GOPATH/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go`
+	if i := strings.LastIndex(file, "/pkg/mod/"); i >= 0 {
+		return file[i+len("/pkg/mod/"):]
+	}
+	// 模块根目录 使用函数所在包的路径确定模块根目录
+	// 函数 `code.gopub.tech/errors/pretty.Formatter`
+	// 文件 `/root/module/pretty/formatter.go`
+	// 结果 `code.gopub.tech/errors/pretty/formatter.go`
+	pkg := strings.TrimSuffix(funcPackage(function), "_test")
+	dir, base := path.Split(file)
+	dir = strings.TrimSuffix(dir, "/")
+	for _, mod := range env.modules {
+		if pkg != mod && !strings.HasPrefix(pkg, mod+"/") {
+			continue
+		}
+		if strings.HasSuffix(dir, pkg[len(mod):]) {
+			return pkg + "/" + base
+		}
+	}
+	// GOPATH `/go/src/code.gopub.tech/example/main.go`
+	if i := strings.LastIndex(file, "/src/"+pkg+"/"); i >= 0 && pkg != "" {
+		return file[i+len("/src/"):]
+	}
+	return file
+}
+
+type buildEnv struct {
+	// goroot 形如 `/usr/local/go/src/`
+	goroot string
+	// modules 主模块及依赖模块的路径 长的在前
+	modules []string
+}
+
+var (
+	buildEnvOnce sync.Once
+	buildEnvInfo buildEnv
+)
+
+// detectBuildEnv 探测编译时的 GOROOT 及模块信息
+func detectBuildEnv() buildEnv {
+	buildEnvOnce.Do(func() {
+		// 以 runtime 包中函数的源文件位置推断 GOROOT
+		pc := reflect.ValueOf(runtime.Gosched).Pointer()
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			file, _ := fn.FileLine(pc)
+			if i := strings.LastIndex(file, "/src/runtime/"); i >= 0 {
+				buildEnvInfo.goroot = file[:i+len("/src/")]
+			}
+		}
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			if bi.Main.Path != "" {
+				buildEnvInfo.modules = append(buildEnvInfo.modules, bi.Main.Path)
+			}
+			for _, dep := range bi.Deps {
+				buildEnvInfo.modules = append(buildEnvInfo.modules, dep.Path)
+			}
+			sort.Slice(buildEnvInfo.modules, func(i, j int) bool {
+				return len(buildEnvInfo.modules[i]) > len(buildEnvInfo.modules[j])
+			})
+		}
+	})
+	return buildEnvInfo
+}
+
+// prettyFunc 美化编译器生成的函数名
+//
+//	`gopkg.in/yaml%2ev3.Unmarshal`     => `gopkg.in/yaml.v3.Unmarshal`
+//	`pkg.(*T).Method-fm`               => `pkg.(*T).Method`
+//	`pkg.(*T).Method.func1.2`          => `pkg.(*T).Method.func1.func2`
+//	`pkg.Map[go.shape.int,go.shape.string]` => `pkg.Map[int,string]`
+func prettyFunc(name string) string {
+	if strings.Contains(name, "%") {
+		if s, err := unescapeFuncName(name); err == nil {
+			name = s
+		}
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	name = strings.ReplaceAll(name, "go.shape.", "")
+
+	// 嵌套闭包 `func1.2.3` => `func1.func2.func3`
+	i := strings.LastIndex(name, "/") + 1
+	parts := strings.Split(name[i:], ".")
+	var inClosure bool
+	for j, part := range parts {
+		if strings.HasPrefix(part, "func") && isDigits(part[len("func"):]) {
+			inClosure = true
+			continue
+		}
+		if inClosure && isDigits(part) {
+			parts[j] = "func" + part
+			continue
+		}
+		inClosure = false
+	}
+	return name[:i] + strings.Join(parts, ".")
+}
+
+// unescapeFuncName 还原函数名中被转义的字符 如 `%2e` => `.`
+func unescapeFuncName(name string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '%' && i+2 < len(name) {
+			b, err := strconv.ParseUint(name[i+1:i+3], 16, 8)
+			if err != nil {
+				return name, err
+			}
+			sb.WriteByte(byte(b))
+			i += 2
+			continue
+		}
+		sb.WriteByte(name[i])
+	}
+	return sb.String(), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
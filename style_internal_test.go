@@ -0,0 +1,21 @@
+package errors
+
+import "testing"
+
+func TestPrettyFunc(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{"code.gopub.tech/errors.New", "code.gopub.tech/errors.New"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3.Unmarshal"},
+		{"pkg.(*T).Method-fm", "pkg.(*T).Method"},
+		{"pkg.(*T).Method.func1", "pkg.(*T).Method.func1"},
+		{"pkg.(*T).Method.func1.2", "pkg.(*T).Method.func1.func2"},
+		{"pkg.(*T).Method.func1.2.3", "pkg.(*T).Method.func1.func2.func3"},
+		{"pkg.Map[go.shape.int,go.shape.string]", "pkg.Map[int,string]"},
+		{"pkg.Map[...].func1.1", "pkg.Map[...].func1.func1"},
+		{"pkg/v2.T.Len", "pkg/v2.T.Len"},
+	} {
+		if got := prettyFunc(c.in); got != c.want {
+			t.Errorf("prettyFunc(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package errors_test
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+type styleT struct{}
+
+func (*styleT) method() error {
+	return errors.New("method")
+}
+
+func TestStackStyle(t *testing.T) {
+	defer errors.SetStackStyle(errors.StackStyle{})
+	err := (&styleT{}).method()
+	raw := errors.NewReport(err).Stack[0]
+	if raw.Function != "code.gopub.tech/errors_test.(*styleT).method" ||
+		!strings.HasPrefix(raw.File, "/") {
+		t.Errorf("zero StackStyle should not rewrite, got %+v", raw)
+	}
+
+	errors.SetStackStyle(errors.StackStyle{
+		TrimPaths:     true,
+		ShortPackages: true,
+		PrettyFuncs:   true,
+	})
+	// 旧版本 Go 的测试程序中没有主模块信息 无法确定模块根目录
+	bi, _ := debug.ReadBuildInfo()
+	file := "code.gopub.tech/errors/style_test.go"
+	if bi == nil || bi.Main.Path == "" {
+		file = raw.File
+	}
+	// 闭包等函数名的美化见 TestPrettyFunc
+	fn := "errors_test.(*styleT).method"
+	r := errors.NewReport(err)
+	if f := r.Stack[0]; f.Function != fn || f.File != file {
+		t.Errorf("unexpected rewritten frame: %+v", f)
+	}
+	for _, f := range r.Stack {
+		if f.Function == "testing.tRunner" && f.File != "testing/testing.go" {
+			t.Errorf("GOROOT not trimmed: %+v", f)
+		}
+	}
+	detail := errors.Detail(err)
+	t.Logf("%s", detail)
+	if !strings.Contains(detail, fn+"\n") ||
+		!strings.Contains(detail, fmt.Sprintf("\t%s:%d\n", file, raw.Line)) {
+		t.Errorf("%%+v should be rewritten")
+	}
+	f := err.(stackTracer).StackTrace()[0]
+	if s := fmt.Sprintf("%+s", f); s != fn+"\n\t"+file {
+		t.Errorf("Frame %%+s should be rewritten, got %q", s)
+	}
+	if f.Function() != raw.Function {
+		t.Errorf("Frame.Function() should keep the raw name, got %s", f.Function())
+	}
+
+	errors.SetStackStyle(errors.StackStyle{TrimPrefixes: []string{strings.TrimSuffix(raw.File, "style_test.go")}})
+	if f := errors.NewReport(err).Stack[0]; f.File != "style_test.go" || f.Function != raw.Function {
+		t.Errorf("TrimPrefixes failed: %+v", f)
+	}
+}
@@ -1,10 +1,9 @@
 package errors
 
-import "errors"
-
-func Is(err, target error) bool {
-	return errors.Is(err, target)
-}
+import (
+	"errors"
+	"reflect"
+)
 
 func As(err error, target any) bool {
 	return errors.As(err, target)
@@ -37,3 +36,62 @@ func UnwrapMulti(err error) []error {
 	}
 	return nil
 }
+
+// IsAny 同 Is，但会搜索整棵错误树：
+// 除 `Unwrap() error`、`Unwrap() []error` 外，还会沿着只实现了 `Cause() error` 的包装错误
+// 以及 WithSecondary 附加的次要错误查找。遍历顺序见 Walk
+func IsAny(err, target error) bool {
+	if err == nil || target == nil {
+		return err == target
+	}
+	comparable := reflect.TypeOf(target).Comparable()
+	var found bool
+	Walk(err, func(node Node) WalkAction {
+		if comparable && node.Err == target {
+			found = true
+		} else if x, ok := node.Err.(interface{ Is(error) bool }); ok && x.Is(target) {
+			found = true
+		}
+		if found {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return found
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// AsAny 同 As，但会搜索整棵错误树，搜索范围同 IsAny。
+// target 必须是指向接口或实现了 error 的类型的非 nil 指针，否则会 panic
+func AsAny(err error, target any) bool {
+	if target == nil {
+		panic("errors: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		panic("errors: target must be a non-nil pointer")
+	}
+	targetType := typ.Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("errors: *target must be interface or implement error")
+	}
+	if err == nil {
+		return false
+	}
+	var found bool
+	Walk(err, func(node Node) WalkAction {
+		if reflect.TypeOf(node.Err).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(node.Err))
+			found = true
+		} else if x, ok := node.Err.(interface{ As(any) bool }); ok && x.As(target) {
+			found = true
+		}
+		if found {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	return found
+}
@@ -0,0 +1,72 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+type isMethod struct{}
+
+func (isMethod) Error() string        { return "isMethod" }
+func (isMethod) Is(target error) bool { return target == io.EOF }
+
+func TestIsAny(t *testing.T) {
+	cleanup := errors.Wrap(context.Canceled, "cleanup")
+	err := errors.WithSecondary(errors.New("request failed"), cleanup)
+	if errors.Is(err, context.Canceled) || !errors.IsAny(err, context.Canceled) {
+		t.Errorf("IsAny should search secondary errors")
+	}
+
+	err = errors.Wrap(&causeOnly{cause: io.ErrUnexpectedEOF}, "read")
+	if errors.Is(err, io.ErrUnexpectedEOF) || !errors.IsAny(err, io.ErrUnexpectedEOF) {
+		t.Errorf("IsAny should follow Cause()")
+	}
+
+	err = errors.Join(errors.New("a"), errors.WithMessage(isMethod{}, "b"))
+	if !errors.IsAny(err, io.EOF) || errors.IsAny(err, io.ErrClosedPipe) {
+		t.Errorf("IsAny should honor Is method in joined errors")
+	}
+
+	cycle := &cycleErr{}
+	cycle.next = &cycleErr{next: cycle}
+	if errors.IsAny(cycle, io.EOF) {
+		t.Errorf("IsAny on cycle")
+	}
+
+	if !errors.IsAny(nil, nil) || errors.IsAny(err, nil) || errors.IsAny(nil, io.EOF) {
+		t.Errorf("IsAny with nil")
+	}
+}
+
+func TestAsAny(t *testing.T) {
+	pathErr := &fs.PathError{Op: "remove", Path: "/tmp/x", Err: fs.ErrPermission}
+	err := errors.WithSecondary(errors.New("save failed"), &causeOnly{cause: pathErr})
+	var target *fs.PathError
+	if errors.As(err, &target) {
+		t.Errorf("As should not search secondary errors")
+	}
+	if !errors.AsAny(err, &target) || target != pathErr {
+		t.Errorf("AsAny should search secondary errors")
+	}
+
+	// 本包的包装错误会转发 Timeout 这里用标准库包装
+	var iface interface{ Timeout() bool }
+	if !errors.AsAny(fmt.Errorf("save: %w", &causeOnly{cause: pathErr}), &iface) || iface != pathErr {
+		t.Errorf("AsAny with interface target")
+	}
+	if errors.AsAny(nil, &target) {
+		t.Errorf("AsAny with nil error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("AsAny with invalid target should panic")
+		}
+	}()
+	errors.AsAny(err, target)
+}
@@ -0,0 +1,117 @@
+package errors
+
+import "reflect"
+
+// EdgeKind 节点与父节点之间的关系
+type EdgeKind int
+
+const (
+	// EdgeRoot 根节点
+	EdgeRoot EdgeKind = iota
+	// EdgeCause 父节点通过 `Cause() error` 或 `Unwrap() error` 包装了本节点
+	EdgeCause
+	// EdgeMulti 父节点通过 `Unwrap() []error` 包装了本节点
+	EdgeMulti
+	// EdgeSecondary 本节点是通过 WithSecondary 附加到父节点上的次要错误
+	EdgeSecondary
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case EdgeRoot:
+		return "root"
+	case EdgeCause:
+		return "cause"
+	case EdgeMulti:
+		return "multi"
+	case EdgeSecondary:
+		return "secondary"
+	}
+	return "unknown"
+}
+
+// Node 遍历错误树时的节点
+type Node struct {
+	// Err 当前错误
+	Err error
+	// Parent 父节点错误 根节点为 nil
+	Parent error
+	// Depth 深度 根节点为 0
+	Depth int
+	// Edge 与父节点的关系
+	Edge EdgeKind
+	// Index 当 Edge 为 EdgeMulti 时，本节点在 `Unwrap() []error` 中的下标
+	Index int
+}
+
+// WalkAction 访问节点后的动作
+type WalkAction int
+
+const (
+	// WalkContinue 继续遍历
+	WalkContinue WalkAction = iota
+	// WalkSkip 跳过当前节点的子节点
+	WalkSkip
+	// WalkStop 停止遍历
+	WalkStop
+)
+
+// Walk 深度优先遍历错误树。
+// 依次访问 `Cause()`/`Unwrap() error` 包装的错误、
+// `Unwrap() []error` 包装的多个错误、以及 WithSecondary 附加的次要错误。
+// 同一个错误出现在多个分支中时每处都会被访问；
+// 错误(可比较时)与其祖先节点相同即视为循环引用，不再访问，因此不会死循环。
+func Walk(err error, fn func(node Node) WalkAction) {
+	if err == nil {
+		return
+	}
+	w := walker{fn: fn, path: map[error]bool{}}
+	w.walk(Node{Err: err, Edge: EdgeRoot})
+}
+
+type walker struct {
+	fn   func(node Node) WalkAction
+	path map[error]bool // 从根节点到当前节点路径上的错误
+}
+
+// walk 返回 false 表示停止遍历
+func (w *walker) walk(node Node) bool {
+	err := node.Err
+	if reflect.TypeOf(err).Comparable() {
+		if w.path[err] {
+			return true
+		}
+		w.path[err] = true
+		defer delete(w.path, err)
+	}
+	switch w.fn(node) {
+	case WalkStop:
+		return false
+	case WalkSkip:
+		return true
+	}
+	child := Node{Parent: err, Depth: node.Depth + 1}
+	if cause := UnwrapOnce(err); cause != nil {
+		child.Err, child.Edge = cause, EdgeCause
+		if !w.walk(child) {
+			return false
+		}
+	}
+	for i, cause := range UnwrapMulti(err) {
+		if cause == nil {
+			continue
+		}
+		child.Err, child.Edge, child.Index = cause, EdgeMulti, i
+		if !w.walk(child) {
+			return false
+		}
+	}
+	child.Index = 0
+	if e, ok := err.(*withSecondaryError); ok && e.secondaryError != nil {
+		child.Err, child.Edge = e.secondaryError, EdgeSecondary
+		if !w.walk(child) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+//go:build go1.23
+
+package errors
+
+import "iter"
+
+// All 返回按 Walk 顺序遍历错误树中每个错误的迭代器
+//
+//	for e := range errors.All(err) {
+//		// ...
+//	}
+func All(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		Walk(err, func(node Node) WalkAction {
+			if !yield(node.Err) {
+				return WalkStop
+			}
+			return WalkContinue
+		})
+	}
+}
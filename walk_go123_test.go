@@ -0,0 +1,23 @@
+//go:build go1.23
+
+package errors_test
+
+import (
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+func TestAll(t *testing.T) {
+	err := errors.Wrap(errors.Join(errFmt, errLeafNew), "prefix")
+	var found bool
+	for e := range errors.All(err) {
+		if e == errLeafNew {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("All should yield errLeafNew")
+	}
+}
@@ -0,0 +1,91 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"code.gopub.tech/errors"
+)
+
+type causeOnly struct{ cause error }
+
+func (e *causeOnly) Error() string { return "causeOnly: " + e.cause.Error() }
+func (e *causeOnly) Cause() error  { return e.cause }
+
+type cycleErr struct{ next error }
+
+func (e *cycleErr) Error() string { return "cycle" }
+func (e *cycleErr) Unwrap() error { return e.next }
+
+func TestWalk(t *testing.T) {
+	errA, errB, errC := fmt.Errorf("a"), fmt.Errorf("b"), fmt.Errorf("c")
+	err := errors.WithSecondary(
+		&causeOnly{cause: errors.Join(errA, errB)},
+		errC,
+	)
+	var got []string
+	errors.Walk(err, func(node errors.Node) errors.WalkAction {
+		got = append(got, fmt.Sprintf("%d:%s:%d:%s", node.Depth, node.Edge, node.Index, node.Err))
+		return errors.WalkContinue
+	})
+	want := []string{
+		"0:root:0:causeOnly: a\nb",
+		"1:cause:0:causeOnly: a\nb",
+		"2:cause:0:a\nb",
+		"3:cause:0:a\nb",
+		"4:multi:0:a",
+		"4:multi:1:b",
+		"1:secondary:0:c",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Walk order mismatch:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestWalkAction(t *testing.T) {
+	err := errors.Wrap(errors.Join(errFmt, errLeafNew), "prefix")
+	var count int
+	errors.Walk(err, func(node errors.Node) errors.WalkAction {
+		count++
+		if node.Edge == errors.EdgeMulti {
+			return errors.WalkStop
+		}
+		return errors.WalkContinue
+	})
+	if count != 5 { // withStack withPrefix withStack joinError errFmt
+		t.Errorf("WalkStop: want 5 nodes, got %d", count)
+	}
+
+	count = 0
+	errors.Walk(err, func(node errors.Node) errors.WalkAction {
+		count++
+		return errors.WalkSkip
+	})
+	if count != 1 {
+		t.Errorf("WalkSkip: want 1 node, got %d", count)
+	}
+
+	cycle := &cycleErr{}
+	cycle.next = &cycleErr{next: cycle}
+	count = 0
+	errors.Walk(cycle, func(node errors.Node) errors.WalkAction {
+		count++
+		return errors.WalkContinue
+	})
+	if count != 2 {
+		t.Errorf("cycle: want 2 nodes, got %d", count)
+	}
+
+	// 同一个错误出现在多个分支中 每处都要访问
+	var indexes []int
+	errors.Walk(errors.Join(io.EOF, io.EOF), func(node errors.Node) errors.WalkAction {
+		if node.Err == io.EOF {
+			indexes = append(indexes, node.Index)
+		}
+		return errors.WalkContinue
+	})
+	if len(indexes) != 2 || indexes[0] != 0 || indexes[1] != 1 {
+		t.Errorf("repeated sentinel: want visits at index 0 and 1, got %v", indexes)
+	}
+}